@@ -32,6 +32,32 @@ func formatGuardrailBackpressure(reason string) string {
 	case strings.HasPrefix(reason, "forbidden_path:"):
 		path := strings.TrimPrefix(reason, "forbidden_path:")
 		return "You attempted to modify forbidden directory: " + path + ". Choose an alternative file/approach."
+	case strings.HasPrefix(reason, "dependency_denied:"), strings.HasPrefix(reason, "dependency_manifest_changed:"):
+		return "Dependency changes are not allowed in this repo. Revert the manifest edit and solve the task with existing dependencies."
+	case strings.HasPrefix(reason, "dependency_not_allowlisted:"):
+		dep := strings.TrimPrefix(reason, "dependency_not_allowlisted:")
+		return "Dependency " + dep + " is not on the allowlist. Remove it and use an allowlisted or existing dependency."
+	case strings.HasPrefix(reason, "dependency_not_in_task:"):
+		dep := strings.TrimPrefix(reason, "dependency_not_in_task:")
+		return "Dependency " + dep + " is not mentioned in the plan task. Remove it, or stop and ask for the plan to name it."
+	case strings.HasPrefix(reason, "hook_error:"):
+		return "A guardrail hook could not evaluate this iteration. Keep changes minimal so the hook can run cleanly."
+	case strings.HasPrefix(reason, "hook:"):
+		return "A repository guardrail hook rejected the change (" + strings.TrimPrefix(reason, "hook:") + "). Follow the hook message below and adjust your approach."
+	case reason == "scope_declaration_missing":
+		return "Declare scope in Phase 0d (\"Will change:\" / \"Will NOT change:\") before modifying files."
+	case reason == "scope_excluded_change":
+		return "You modified files you declared as \"Will NOT change\". Revert those changes or repeat Phase 0d with a corrected scope."
+	case reason == "scope_undeclared_change":
+		return "You modified files outside your declared \"Will change\" scope. Revert them or repeat Phase 0d and declare them."
+	case strings.HasPrefix(reason, "large_file:"), strings.HasPrefix(reason, "binary_file:"):
+		return "You added large or binary files (build outputs, coverage, binaries). Remove them from the change, add them to .gitignore if generated, and commit source only."
+	case strings.HasPrefix(reason, "commit_policy:"):
+		return "Your commits violate the repository commit policy. Amend or reword them (git commit --amend / git rebase) so every listed commit complies, then continue."
+	case reason == "commit_policy_invalid":
+		return "commit_policy in rauf.yaml is invalid. Do not change commits; the operator must fix the configuration."
+	case reason == "git_error_commit_list":
+		return "rauf could not read the commits from this iteration. Keep history linear and avoid rewriting commits you did not create."
 	case reason == "max_files_changed":
 		return "Reduce scope: modify fewer files. Prefer smaller, focused patches."
 	case reason == "max_commits_exceeded":
@@ -179,7 +205,13 @@ func buildBackpressurePack(state raufState, gitAvailable bool) string {
 		b.WriteString("`\n")
 		b.WriteString("- Action Required: ")
 		b.WriteString(formatGuardrailBackpressure(state.PriorGuardrailReason))
-		b.WriteString("\n\n")
+		b.WriteString("\n")
+		if state.PriorGuardrailMessage != "" {
+			b.WriteString("- Details:\n\n```\n")
+			b.WriteString(state.PriorGuardrailMessage)
+			b.WriteString("\n```\n")
+		}
+		b.WriteString("\n")
 	}
 
 	// Verification failure
@@ -251,8 +283,13 @@ func buildBackpressurePack(state raufState, gitAvailable bool) string {
 		if state.PriorRetryReason != "" {
 			b.WriteString("- Matched: `" + state.PriorRetryReason + "`\n")
 		}
-		b.WriteString("- Note: The harness experienced transient failures (e.g., rate limits).\n")
-		b.WriteString("- Action: Keep responses concise, avoid large file dumps, reduce tool calls per iteration.\n\n")
+		if state.PriorRetryReason == harnessIdleReason {
+			b.WriteString("- Note: The harness stalled with no output and was restarted.\n")
+			b.WriteString("- Action: Avoid long silent commands; run slow tests in smaller pieces or with progress output.\n\n")
+		} else {
+			b.WriteString("- Note: The harness experienced transient failures (e.g., rate limits).\n")
+			b.WriteString("- Action: Keep responses concise, avoid large file dumps, reduce tool calls per iteration.\n\n")
+		}
 	}
 
 	b.WriteString("---\n\n")
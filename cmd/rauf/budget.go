@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// modelPrice is the USD cost per million input and output tokens.
+type modelPrice struct {
+	InputPerMTok  float64
+	OutputPerMTok float64
+}
+
+// tokenUsageConfig extracts token counts from harness output when the adapter
+// does not report them. Patterns are regexes whose first capture group is the
+// count; JSON paths are dotted keys looked up in JSON lines (last match wins).
+type tokenUsageConfig struct {
+	InputPattern  string
+	OutputPattern string
+	InputJSON     string
+	OutputJSON    string
+}
+
+func (c tokenUsageConfig) enabled() bool {
+	return c.InputPattern != "" || c.OutputPattern != "" || c.InputJSON != "" || c.OutputJSON != ""
+}
+
+func (u tokenUsage) total() int {
+	return u.InputTokens + u.OutputTokens
+}
+
+// parseModelPrice accepts "input/output" prices, e.g. "3.00/15.00".
+func parseModelPrice(value string) (modelPrice, bool) {
+	in, out, ok := strings.Cut(strings.TrimPrefix(strings.TrimSpace(value), "$"), "/")
+	if !ok {
+		return modelPrice{}, false
+	}
+	inPrice, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimPrefix(in, "$")), 64)
+	if err != nil || inPrice < 0 {
+		return modelPrice{}, false
+	}
+	outPrice, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimPrefix(out, "$")), 64)
+	if err != nil || outPrice < 0 {
+		return modelPrice{}, false
+	}
+	return modelPrice{InputPerMTok: inPrice, OutputPerMTok: outPrice}, true
+}
+
+// priceForModel looks up the model's price, falling back to the "default" entry.
+func priceForModel(pricing map[string]modelPrice, model string) (modelPrice, bool) {
+	if price, ok := pricing[model]; ok && model != "" {
+		return price, true
+	}
+	price, ok := pricing["default"]
+	return price, ok
+}
+
+func estimateCost(pricing map[string]modelPrice, model string, usage tokenUsage) float64 {
+	price, ok := priceForModel(pricing, model)
+	if !ok {
+		return 0
+	}
+	return float64(usage.InputTokens)/1e6*price.InputPerMTok + float64(usage.OutputTokens)/1e6*price.OutputPerMTok
+}
+
+// extractTokenUsage applies the configured patterns and JSON paths to raw output.
+func extractTokenUsage(cfg tokenUsageConfig, raw string) (tokenUsage, bool) {
+	usage := tokenUsage{}
+	found := false
+	if n, ok := lastPatternCount(cfg.InputPattern, raw); ok {
+		usage.InputTokens, found = n, true
+	} else if n, ok := lastJSONCount(cfg.InputJSON, raw); ok {
+		usage.InputTokens, found = n, true
+	}
+	if n, ok := lastPatternCount(cfg.OutputPattern, raw); ok {
+		usage.OutputTokens, found = n, true
+	} else if n, ok := lastJSONCount(cfg.OutputJSON, raw); ok {
+		usage.OutputTokens, found = n, true
+	}
+	return usage, found
+}
+
+func lastPatternCount(pattern, raw string) (int, bool) {
+	if pattern == "" {
+		return 0, false
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return 0, false
+	}
+	matches := re.FindAllStringSubmatch(stripANSI(raw), -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		if len(matches[i]) < 2 {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.ReplaceAll(matches[i][1], ",", "")); err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+func lastJSONCount(path, raw string) (int, bool) {
+	if path == "" {
+		return 0, false
+	}
+	keys := strings.Split(strings.TrimPrefix(path, "$."), ".")
+	count, found := 0, false
+	forEachJSONLine(raw, func(line []byte) {
+		var doc interface{}
+		if err := json.Unmarshal(line, &doc); err != nil {
+			return
+		}
+		for _, key := range keys {
+			obj, ok := doc.(map[string]interface{})
+			if !ok {
+				return
+			}
+			if doc, ok = obj[key]; !ok {
+				return
+			}
+		}
+		if n, ok := doc.(float64); ok {
+			count, found = int(n), true
+		}
+	})
+	return count, found
+}
+
+// budgetExceeded reports which budget, if any, the run has used up.
+// task may be empty to skip the per-task check.
+func budgetExceeded(cfg runtimeConfig, report *RunReport, task string) (string, bool) {
+	if report == nil {
+		return "", false
+	}
+	if cfg.MaxTokensPerRun > 0 && report.TotalTokens >= cfg.MaxTokensPerRun {
+		return fmt.Sprintf("max_tokens_per_run (%d >= %d)", report.TotalTokens, cfg.MaxTokensPerRun), true
+	}
+	if cfg.MaxCostPerRun > 0 && report.TotalCost >= cfg.MaxCostPerRun {
+		return fmt.Sprintf("max_cost_per_run ($%.4f >= $%.4f)", report.TotalCost, cfg.MaxCostPerRun), true
+	}
+	if task != "" && cfg.MaxCostPerTask > 0 && report.TaskCosts[task] >= cfg.MaxCostPerTask {
+		return fmt.Sprintf("max_cost_per_task ($%.4f >= $%.4f)", report.TaskCosts[task], cfg.MaxCostPerTask), true
+	}
+	return "", false
+}
+
+// recordUsage adds an iteration's usage to the run totals.
+func recordUsage(report *RunReport, task string, usage tokenUsage, cost float64) {
+	if report == nil {
+		return
+	}
+	report.TotalInputTokens += usage.InputTokens
+	report.TotalOutputTokens += usage.OutputTokens
+	report.TotalTokens += usage.total()
+	report.TotalCost += cost
+	if task != "" && cost > 0 {
+		if report.TaskCosts == nil {
+			report.TaskCosts = map[string]float64{}
+		}
+		report.TaskCosts[task] += cost
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestParseModelPrice(t *testing.T) {
+	price, ok := parseModelPrice("$3.00/$15")
+	if !ok || price.InputPerMTok != 3 || price.OutputPerMTok != 15 {
+		t.Fatalf("unexpected price: %+v ok=%t", price, ok)
+	}
+	for _, bad := range []string{"3.00", "a/b", "-1/2", ""} {
+		if _, ok := parseModelPrice(bad); ok {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	pricing := map[string]modelPrice{
+		"default": {InputPerMTok: 1, OutputPerMTok: 2},
+		"opus":    {InputPerMTok: 15, OutputPerMTok: 75},
+	}
+	usage := tokenUsage{InputTokens: 1_000_000, OutputTokens: 100_000}
+	if got := estimateCost(pricing, "opus", usage); math.Abs(got-22.5) > 1e-9 {
+		t.Fatalf("opus cost = %f, want 22.5", got)
+	}
+	if got := estimateCost(pricing, "unknown", usage); math.Abs(got-1.2) > 1e-9 {
+		t.Fatalf("default cost = %f, want 1.2", got)
+	}
+	if got := estimateCost(nil, "opus", usage); got != 0 {
+		t.Fatalf("expected zero cost without pricing, got %f", got)
+	}
+}
+
+func TestExtractTokenUsage(t *testing.T) {
+	raw := "Tokens used: input=1,200 output=300\n" +
+		`{"usage":{"input_tokens":10,"output_tokens":4}}` + "\n" +
+		"Tokens used: input=1,500 output=350\n"
+	usage, ok := extractTokenUsage(tokenUsageConfig{
+		InputPattern:  `input=([\d,]+)`,
+		OutputPattern: `output=(\d+)`,
+	}, raw)
+	if !ok || usage.InputTokens != 1500 || usage.OutputTokens != 350 {
+		t.Fatalf("unexpected regex usage: %+v ok=%t", usage, ok)
+	}
+
+	usage, ok = extractTokenUsage(tokenUsageConfig{InputJSON: "usage.input_tokens", OutputJSON: "$.usage.output_tokens"}, raw)
+	if !ok || usage.InputTokens != 10 || usage.OutputTokens != 4 {
+		t.Fatalf("unexpected json usage: %+v ok=%t", usage, ok)
+	}
+
+	if _, ok := extractTokenUsage(tokenUsageConfig{InputPattern: `nothing=(\d+)`}, raw); ok {
+		t.Fatalf("expected no usage match")
+	}
+}
+
+func TestBudgetExceeded(t *testing.T) {
+	report := &RunReport{}
+	cfg := runtimeConfig{MaxTokensPerRun: 1000, MaxCostPerRun: 5, MaxCostPerTask: 1}
+	recordUsage(report, "T1: a", tokenUsage{InputTokens: 100, OutputTokens: 50}, 0.5)
+	if reason, exceeded := budgetExceeded(cfg, report, "T1: a"); exceeded {
+		t.Fatalf("unexpected budget stop: %s", reason)
+	}
+	recordUsage(report, "T1: a", tokenUsage{InputTokens: 100}, 0.6)
+	if _, exceeded := budgetExceeded(cfg, report, "T1: a"); !exceeded {
+		t.Fatalf("expected per-task budget stop")
+	}
+	if _, exceeded := budgetExceeded(cfg, report, "T2: b"); exceeded {
+		t.Fatalf("per-task budget must not apply to other tasks")
+	}
+	recordUsage(report, "", tokenUsage{InputTokens: 800}, 0)
+	if reason, exceeded := budgetExceeded(cfg, report, ""); !exceeded || reason == "" {
+		t.Fatalf("expected token budget stop")
+	}
+	if report.TotalTokens != 1050 || report.TaskCosts["T1: a"] != 1.1 {
+		t.Fatalf("unexpected totals: %+v", report)
+	}
+}
+
+func TestRunModeStopsOnBudget(t *testing.T) {
+	dir := t.TempDir()
+	chdirTemp(t, dir)
+	if err := os.WriteFile("PROMPT_plan.md", []byte("plan"), 0o644); err != nil {
+		t.Fatalf("write prompt failed: %v", err)
+	}
+
+	orig := runHarnessOnce
+	defer func() { runHarnessOnce = orig }()
+	calls := 0
+	runHarnessOnce = func(ctx context.Context, prompt string, harness, harnessArgs string, logFile *os.File, runner runtimeExec) (string, error) {
+		calls++
+		return "working\ntokens in=1000 out=200\n", nil
+	}
+
+	cfg := modeConfig{mode: "plan", promptFile: "PROMPT_plan.md", maxIterations: 5}
+	fileCfg := runtimeConfig{
+		NoProgressIters: 10,
+		TokenUsage:      tokenUsageConfig{InputPattern: `in=(\d+)`, OutputPattern: `out=(\d+)`},
+		Pricing:         map[string]modelPrice{"default": {InputPerMTok: 3, OutputPerMTok: 15}},
+		MaxTokensPerRun: 2000,
+	}
+	report := &RunReport{}
+	res, err := runMode(context.Background(), cfg, fileCfg, runtimeExec{Quiet: true}, raufState{}, false, "", "IMPLEMENTATION_PLAN.md", "harness", "", true, "logs", false, 0, 0, 0, false, nil, 0, nil, nil, report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.ExitReason != "budget_exceeded" || calls != 2 {
+		t.Fatalf("expected budget stop after 2 runs, got reason=%q calls=%d", res.ExitReason, calls)
+	}
+	if report.TotalTokens != 2400 || math.Abs(report.TotalCost-0.012) > 1e-9 {
+		t.Fatalf("unexpected report totals: tokens=%d cost=%f", report.TotalTokens, report.TotalCost)
+	}
+	if len(report.Iterations) != 2 || report.Iterations[0].InputTokens != 1000 || report.Iterations[1].ExitReason != "budget_exceeded" {
+		t.Fatalf("unexpected iteration stats: %+v", report.Iterations)
+	}
+}
+
+func TestParseConfigBudget(t *testing.T) {
+	cfg := runtimeConfig{}
+	data := "max_tokens_per_run: 500000\nmax_cost_per_run: $12.50\nmax_cost_per_task: 2\n" +
+		"pricing:\n  default: 1/5\n  claude-opus-4: \"15.00/75.00\"\n" +
+		"token_usage:\n  input_pattern: \"input: (\\d+)\"\n  output_json: usage.output_tokens\n"
+	if err := parseConfigBytes([]byte(data), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if cfg.MaxTokensPerRun != 500000 || cfg.MaxCostPerRun != 12.5 || cfg.MaxCostPerTask != 2 {
+		t.Fatalf("unexpected budgets: %+v", cfg)
+	}
+	if cfg.Pricing["claude-opus-4"].OutputPerMTok != 75 || cfg.Pricing["default"].InputPerMTok != 1 {
+		t.Fatalf("unexpected pricing: %+v", cfg.Pricing)
+	}
+	if cfg.TokenUsage.InputPattern != `input: (\d+)` || cfg.TokenUsage.OutputJSON != "usage.output_tokens" {
+		t.Fatalf("unexpected token usage config: %+v", cfg.TokenUsage)
+	}
+}
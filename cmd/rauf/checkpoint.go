@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Checkpoint statuses. Only completed runs are skipped by `rauf resume`.
+const (
+	checkpointRunning    = "running"
+	checkpointIncomplete = "incomplete"
+	checkpointCompleted  = "completed"
+)
+
+// runsDir holds one checkpoint per run, named by run ID.
+var runsDir = filepath.Join(".rauf", "runs")
+
+// checkpointPromptFiles are hashed so `rauf resume` can warn about edits.
+var checkpointPromptFiles = []string{"PROMPT_architect.md", "PROMPT_plan.md", "PROMPT_build.md"}
+
+// runCheckpoint records enough of a run to continue it after a crash, a
+// --timeout or a reboot: the CLI args and config snapshot it started with,
+// its strategy position and the report so far.
+type runCheckpoint struct {
+	ID           string            `json:"id"`
+	Status       string            `json:"status"`
+	Error        string            `json:"error,omitempty"`
+	StartedAt    time.Time         `json:"started_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	Args         []string          `json:"args"`
+	ConfigPath   string            `json:"config_path"`
+	ConfigFound  bool              `json:"config_found"`
+	ConfigData   string            `json:"config_data,omitempty"`
+	PromptHashes map[string]string `json:"prompt_hashes,omitempty"`
+	PlanPath     string            `json:"plan_path,omitempty"`
+	PlanHash     string            `json:"plan_hash,omitempty"`
+	// StartHead is HEAD when the run started; pushes check commits from here
+	// when the branch has no upstream.
+	StartHead     string           `json:"start_head,omitempty"`
+	StepIndex     int              `json:"step_index"`
+	StepIteration int              `json:"step_iteration"`
+	Iterations    int              `json:"iterations"`
+	Counters      strategyCounters `json:"counters"`
+	LastResult    iterationResult  `json:"last_result"`
+	Report        RunReport        `json:"report"`
+}
+
+type checkpointKey struct{}
+
+func withRunCheckpoint(ctx context.Context, cp *runCheckpoint) context.Context {
+	return context.WithValue(ctx, checkpointKey{}, cp)
+}
+
+// runCheckpointFrom returns the run's checkpoint, or nil. All checkpoint
+// methods accept a nil receiver so callers need no checks.
+func runCheckpointFrom(ctx context.Context) *runCheckpoint {
+	cp, _ := ctx.Value(checkpointKey{}).(*runCheckpoint)
+	return cp
+}
+
+func newRunID(now time.Time) string {
+	buf := make([]byte, 3)
+	_, _ = rand.Read(buf)
+	return now.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(buf)
+}
+
+func newRunCheckpoint(args []string, configPath, planPath string) *runCheckpoint {
+	now := time.Now().UTC()
+	cp := &runCheckpoint{
+		ID:           newRunID(now),
+		Status:       checkpointRunning,
+		StartedAt:    now,
+		Args:         append([]string(nil), args...),
+		ConfigPath:   configPath,
+		PromptHashes: map[string]string{},
+		PlanPath:     planPath,
+	}
+	if data, err := os.ReadFile(configPath); err == nil {
+		cp.ConfigFound = true
+		cp.ConfigData = string(data)
+	}
+	for _, name := range checkpointPromptFiles {
+		if hash := fileHash(name); hash != "" {
+			cp.PromptHashes[name] = hash
+		}
+	}
+	return cp
+}
+
+func runCheckpointPath(id string) string {
+	return filepath.Join(runsDir, id+".json")
+}
+
+func (cp *runCheckpoint) save() error {
+	if cp == nil {
+		return nil
+	}
+	cp.UpdatedAt = time.Now().UTC()
+	if cp.PlanPath != "" {
+		cp.PlanHash = fileHash(cp.PlanPath)
+	}
+	if err := os.MkdirAll(runsDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	tempFile, err := os.CreateTemp(runsDir, ".run-*.json.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := tempFile.Name()
+	if _, err := tempFile.Write(data); err != nil {
+		tempFile.Close()
+		os.Remove(tempPath)
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	if err := os.Rename(tempPath, runCheckpointPath(cp.ID)); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	return nil
+}
+
+func (cp *runCheckpoint) saveOrWarn() {
+	if err := cp.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save run checkpoint: %v\n", err)
+	}
+}
+
+// recordIteration is called by runMode after every completed iteration.
+func (cp *runCheckpoint) recordIteration(result iterationResult, report *RunReport) {
+	if cp == nil {
+		return
+	}
+	cp.Iterations++
+	cp.LastResult = result
+	cp.Report = *report
+	cp.saveOrWarn()
+}
+
+// recordStep stores the strategy position: the next iteration to run is
+// iteration `iteration` of step `step`.
+func (cp *runCheckpoint) recordStep(step, iteration int, sc strategyContext) {
+	if cp == nil {
+		return
+	}
+	cp.StepIndex = step
+	cp.StepIteration = iteration
+	cp.LastResult = sc.Last
+	cp.Counters = sc.strategyCounters
+	cp.saveOrWarn()
+}
+
+// strategyPosition returns where a (possibly resumed) strategy run starts.
+func (cp *runCheckpoint) strategyPosition() (int, int, strategyContext) {
+	if cp == nil {
+		return 0, 0, strategyContext{}
+	}
+	return cp.StepIndex, cp.StepIteration, strategyContext{Last: cp.LastResult, strategyCounters: cp.Counters}
+}
+
+// finish marks the run completed, or incomplete when it failed or the last
+// iteration was interrupted or stopped, so it can be resumed.
+func (cp *runCheckpoint) finish(err error, report *RunReport) {
+	if cp == nil {
+		return
+	}
+	exitReason := ""
+	if n := len(report.Iterations); n > 0 {
+		exitReason = report.Iterations[n-1].ExitReason
+	}
+	cp.Status = checkpointCompleted
+	cp.Error = ""
+	if err != nil {
+		cp.Status = checkpointIncomplete
+		cp.Error = err.Error()
+	} else if exitReason == interruptExitReason || exitReason == stoppedByUserReason {
+		cp.Status = checkpointIncomplete
+	}
+	cp.Report = *report
+	cp.saveOrWarn()
+}
+
+// changedInputs lists the config, prompt and plan files that differ from
+// what the checkpoint recorded.
+func (cp *runCheckpoint) changedInputs() []string {
+	changed := []string{}
+	data, err := os.ReadFile(cp.ConfigPath)
+	if found := err == nil; found != cp.ConfigFound || string(data) != cp.ConfigData {
+		changed = append(changed, cp.ConfigPath)
+	}
+	for _, name := range checkpointPromptFiles {
+		if fileHash(name) != cp.PromptHashes[name] {
+			changed = append(changed, name)
+		}
+	}
+	if cp.PlanPath != "" && fileHash(cp.PlanPath) != cp.PlanHash {
+		changed = append(changed, cp.PlanPath)
+	}
+	return changed
+}
+
+// loadRunCheckpoint loads a checkpoint by ID, or the most recently updated
+// unfinished one when id is empty.
+func loadRunCheckpoint(id string) (*runCheckpoint, error) {
+	if id != "" {
+		cp, err := readRunCheckpoint(runCheckpointPath(id))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no run checkpoint %q in %s", id, runsDir)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if cp.Status == checkpointCompleted {
+			return nil, fmt.Errorf("run %s already completed", id)
+		}
+		return cp, nil
+	}
+	paths, _ := filepath.Glob(filepath.Join(runsDir, "*.json"))
+	var candidates []*runCheckpoint
+	for _, path := range paths {
+		cp, err := readRunCheckpoint(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", path, err)
+			continue
+		}
+		if cp.Status != checkpointCompleted {
+			candidates = append(candidates, cp)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no unfinished runs in %s", runsDir)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].UpdatedAt.After(candidates[j].UpdatedAt)
+	})
+	return candidates[0], nil
+}
+
+func readRunCheckpoint(path string) (*runCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cp runCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+	if strings.TrimSpace(cp.ID) == "" {
+		return nil, fmt.Errorf("invalid checkpoint %s: missing id", path)
+	}
+	return &cp, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRunCheckpointSaveAndLoad(t *testing.T) {
+	chdirTemp(t, t.TempDir())
+
+	older := &runCheckpoint{ID: "run-a", Status: checkpointIncomplete}
+	if err := older.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	newer := &runCheckpoint{ID: "run-b", Status: checkpointIncomplete, StepIndex: 2, Args: []string{"plan"}}
+	if err := newer.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	done := &runCheckpoint{ID: "run-c", Status: checkpointCompleted}
+	if err := done.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	cp, err := loadRunCheckpoint("")
+	if err != nil || cp.ID != "run-b" || cp.StepIndex != 2 || cp.Args[0] != "plan" {
+		t.Fatalf("expected latest unfinished run, got %+v err=%v", cp, err)
+	}
+	if cp, err := loadRunCheckpoint("run-a"); err != nil || cp.ID != "run-a" {
+		t.Fatalf("expected run-a, got %+v err=%v", cp, err)
+	}
+	if _, err := loadRunCheckpoint("run-c"); err == nil {
+		t.Fatalf("completed runs must not be resumable")
+	}
+	if _, err := loadRunCheckpoint("missing"); err == nil {
+		t.Fatalf("expected error for unknown run")
+	}
+}
+
+func TestRunCheckpointChangedInputs(t *testing.T) {
+	chdirTemp(t, t.TempDir())
+	for name, content := range map[string]string{"rauf.yaml": "harness: claude\n", "PROMPT_build.md": "build", "PLAN.md": "- [ ] T1"} {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	cp := newRunCheckpoint([]string{"5"}, "rauf.yaml", "PLAN.md")
+	if err := cp.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if changed := cp.changedInputs(); len(changed) != 0 {
+		t.Fatalf("expected no changes, got %v", changed)
+	}
+
+	_ = os.WriteFile("rauf.yaml", []byte("harness: codex\n"), 0o644)
+	_ = os.WriteFile("PROMPT_plan.md", []byte("new prompt"), 0o644)
+	_ = os.WriteFile("PLAN.md", []byte("- [x] T1"), 0o644)
+	changed := cp.changedInputs()
+	want := []string{"rauf.yaml", "PROMPT_plan.md", "PLAN.md"}
+	if len(changed) != len(want) {
+		t.Fatalf("expected %v, got %v", want, changed)
+	}
+	for i := range want {
+		if changed[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, changed)
+		}
+	}
+}
+
+func TestRunStrategyResumesFromCheckpoint(t *testing.T) {
+	chdirTemp(t, t.TempDir())
+
+	origRunMode := runMode
+	defer func() { runMode = origRunMode }()
+	calls := map[string]int{}
+	runMode = func(ctx context.Context, cfg modeConfig, fileCfg runtimeConfig, runner runtimeExec, state raufState, gitAvailable bool, branch, planPath, harness, harnessArgs string, noPush bool, logDir string, retryEnabled bool, retryMaxAttempts int, retryBackoffBase, retryBackoffMax time.Duration, retryJitter bool, retryMatch []string, startNoProgress int, stdin io.Reader, stdout io.Writer, report *RunReport) (iterationResult, error) {
+		calls[cfg.mode]++
+		if startNoProgress != 1 && calls[cfg.mode] == 1 {
+			t.Errorf("expected NoProgress to be restored, got %d", startNoProgress)
+		}
+		return iterationResult{NoProgress: startNoProgress}, nil
+	}
+
+	cp := &runCheckpoint{ID: "run-x", StepIndex: 1, StepIteration: 1, LastResult: iterationResult{NoProgress: 1}}
+	ctx := withRunCheckpoint(context.Background(), cp)
+	fileCfg := runtimeConfig{Strategy: []strategyStep{
+		{Mode: "plan", Iterations: 2},
+		{Mode: "build", Iterations: 3},
+	}}
+	if err := runStrategy(ctx, modeConfig{}, fileCfg, runtimeExec{}, raufState{}, false, "", "", "", "", true, "logs", false, 0, 0, 0, false, nil, nil, nil, &RunReport{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls["plan"] != 0 || calls["build"] != 2 {
+		t.Fatalf("expected 2 remaining build iterations, got %v", calls)
+	}
+	if cp.StepIndex != 2 || cp.StepIteration != 0 {
+		t.Fatalf("expected checkpoint past the last step, got step=%d iter=%d", cp.StepIndex, cp.StepIteration)
+	}
+}
+
+func TestRunMainResume(t *testing.T) {
+	chdirTemp(t, t.TempDir())
+	if err := os.WriteFile("rauf.yaml", []byte("no_push: true\n"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	origRunMode := runMode
+	defer func() { runMode = origRunMode }()
+	var maxIterations []int
+	runMode = func(ctx context.Context, cfg modeConfig, fileCfg runtimeConfig, runner runtimeExec, state raufState, gitAvailable bool, branch, planPath, harness, harnessArgs string, noPush bool, logDir string, retryEnabled bool, retryMaxAttempts int, retryBackoffBase, retryBackoffMax time.Duration, retryJitter bool, retryMatch []string, startNoProgress int, stdin io.Reader, stdout io.Writer, report *RunReport) (iterationResult, error) {
+		maxIterations = append(maxIterations, cfg.maxIterations)
+		if !noPush {
+			t.Errorf("expected the config snapshot to be used")
+		}
+		report.Iterations = append(report.Iterations, IterationStats{Iteration: 1, Mode: cfg.mode})
+		runCheckpointFrom(ctx).recordIteration(iterationResult{}, report)
+		if len(maxIterations) == 1 {
+			return iterationResult{}, errors.New("harness run failed")
+		}
+		return iterationResult{}, nil
+	}
+
+	if code := runMain([]string{"3"}); code != 1 {
+		t.Fatalf("expected failure exit code, got %d", code)
+	}
+	cp, err := loadRunCheckpoint("")
+	if err != nil || cp.Status != checkpointIncomplete || cp.Iterations != 1 || cp.Error == "" {
+		t.Fatalf("expected incomplete checkpoint, got %+v err=%v", cp, err)
+	}
+
+	// The snapshot wins over later config edits.
+	_ = os.WriteFile("rauf.yaml", []byte("no_push: false\n"), 0o644)
+	if code := runMain([]string{"resume", cp.ID}); code != 0 {
+		t.Fatalf("expected resume to succeed, got %d", code)
+	}
+	if len(maxIterations) != 2 || maxIterations[0] != 3 || maxIterations[1] != 2 {
+		t.Fatalf("expected resume with remaining iterations, got %v", maxIterations)
+	}
+	resumed, err := readRunCheckpoint(runCheckpointPath(cp.ID))
+	if err != nil || resumed.Status != checkpointCompleted || resumed.Iterations != 2 || len(resumed.Report.Iterations) != 2 {
+		t.Fatalf("unexpected checkpoint after resume: %+v err=%v", resumed, err)
+	}
+	if _, err := loadRunCheckpoint(""); err == nil {
+		t.Fatalf("expected no unfinished runs left")
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// commitPolicyConfig describes the checks applied to each commit created during
+// a build iteration. An empty config disables the guardrail.
+type commitPolicyConfig struct {
+	MessagePattern   string
+	RequireTaskID    bool
+	ForbidFixup      bool
+	AuthorName       string
+	AuthorEmail      string
+	RequiredTrailers []string
+}
+
+func (c commitPolicyConfig) enabled() bool {
+	return c.MessagePattern != "" || c.RequireTaskID || c.ForbidFixup ||
+		c.AuthorName != "" || c.AuthorEmail != "" || len(c.RequiredTrailers) > 0
+}
+
+type commitInfo struct {
+	Hash           string
+	AuthorName     string
+	AuthorEmail    string
+	CommitterName  string
+	CommitterEmail string
+	Message        string
+}
+
+// subject returns the first line of the commit message.
+func (c commitInfo) subject() string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+	return strings.TrimSpace(subject)
+}
+
+func (c commitInfo) shortHash() string {
+	if len(c.Hash) > 8 {
+		return c.Hash[:8]
+	}
+	return c.Hash
+}
+
+type commitViolation struct {
+	Commit commitInfo
+	Rule   string
+	Detail string
+}
+
+var (
+	taskIDPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]*[-_]?\d+)\s*[:.)\-]`)
+	fixupSubject  = regexp.MustCompile(`(?i)^(fixup!|squash!|amend!|wip\b|\[wip\])`)
+	trailerLine   = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*):\s*(.*)$`)
+)
+
+const (
+	commitLogField = "\x1f"
+	commitLogEnd   = "\x1e"
+)
+
+// listCommits returns the commits in headBefore..headAfter, oldest first.
+func listCommits(headBefore, headAfter string) ([]commitInfo, error) {
+	format := strings.Join([]string{"%H", "%an", "%ae", "%cn", "%ce", "%B"}, "%x1f") + "%x1e"
+	out, err := gitStdout("log", "--reverse", "--format="+format, headBefore+".."+headAfter)
+	if err != nil {
+		return nil, err
+	}
+	commits := []commitInfo{}
+	for _, record := range strings.Split(out, commitLogEnd) {
+		record = strings.TrimLeft(record, "\n")
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+		fields := strings.SplitN(record, commitLogField, 6)
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("unexpected git log record: %q", record)
+		}
+		commits = append(commits, commitInfo{
+			Hash:           fields[0],
+			AuthorName:     fields[1],
+			AuthorEmail:    fields[2],
+			CommitterName:  fields[3],
+			CommitterEmail: fields[4],
+			Message:        strings.TrimSpace(fields[5]),
+		})
+	}
+	return commits, nil
+}
+
+// taskIDFromTitle extracts a leading task identifier such as "T3" from a plan
+// task title like "T3: Enforce unique user email".
+func taskIDFromTitle(title string) string {
+	match := taskIDPattern.FindStringSubmatch(strings.TrimSpace(title))
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// commitTrailers parses "Key: value" trailers from the last paragraph of a message.
+func commitTrailers(message string) map[string]string {
+	trailers := map[string]string{}
+	paragraphs := strings.Split(strings.TrimSpace(message), "\n\n")
+	if len(paragraphs) < 2 {
+		return trailers
+	}
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		match := trailerLine.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			return map[string]string{}
+		}
+		trailers[strings.ToLower(match[1])] = strings.TrimSpace(match[2])
+	}
+	return trailers
+}
+
+// containsTaskID reports whether text references id as a whole word.
+func containsTaskID(text, id string) bool {
+	pattern := regexp.MustCompile(`(?i)(^|[^A-Za-z0-9])` + regexp.QuoteMeta(id) + `($|[^A-Za-z0-9])`)
+	return pattern.MatchString(text)
+}
+
+// checkCommitPolicy returns every violation across the given commits.
+// The task ID check is skipped when the active task title has no ID.
+func checkCommitPolicy(policy commitPolicyConfig, task planTask, commits []commitInfo) ([]commitViolation, error) {
+	var messageRe *regexp.Regexp
+	if policy.MessagePattern != "" {
+		re, err := regexp.Compile(policy.MessagePattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit_policy.message_pattern: %w", err)
+		}
+		messageRe = re
+	}
+	taskID := taskIDFromTitle(task.TitleLine)
+
+	violations := []commitViolation{}
+	for _, commit := range commits {
+		subject := commit.subject()
+		if policy.ForbidFixup && fixupSubject.MatchString(subject) {
+			violations = append(violations, commitViolation{Commit: commit, Rule: "fixup", Detail: "fixup/WIP commits must be squashed before push"})
+		}
+		if messageRe != nil && !messageRe.MatchString(subject) {
+			violations = append(violations, commitViolation{Commit: commit, Rule: "message", Detail: fmt.Sprintf("subject does not match %q", policy.MessagePattern)})
+		}
+		if policy.RequireTaskID && taskID != "" && !containsTaskID(commit.Message, taskID) {
+			violations = append(violations, commitViolation{Commit: commit, Rule: "task_id", Detail: fmt.Sprintf("message must reference task %s", taskID)})
+		}
+		if policy.AuthorName != "" && (commit.AuthorName != policy.AuthorName || commit.CommitterName != policy.AuthorName) {
+			violations = append(violations, commitViolation{Commit: commit, Rule: "identity", Detail: fmt.Sprintf("author/committer name must be %q (got %q / %q)", policy.AuthorName, commit.AuthorName, commit.CommitterName)})
+		}
+		if policy.AuthorEmail != "" && (!strings.EqualFold(commit.AuthorEmail, policy.AuthorEmail) || !strings.EqualFold(commit.CommitterEmail, policy.AuthorEmail)) {
+			violations = append(violations, commitViolation{Commit: commit, Rule: "identity", Detail: fmt.Sprintf("author/committer email must be %q (got %q / %q)", policy.AuthorEmail, commit.AuthorEmail, commit.CommitterEmail)})
+		}
+		if len(policy.RequiredTrailers) > 0 {
+			trailers := commitTrailers(commit.Message)
+			for _, name := range policy.RequiredTrailers {
+				if value, ok := trailers[strings.ToLower(name)]; !ok || value == "" {
+					violations = append(violations, commitViolation{Commit: commit, Rule: "trailer", Detail: fmt.Sprintf("missing %s: trailer", name)})
+				}
+			}
+		}
+	}
+	return violations, nil
+}
+
+// enforceCommitPolicy checks the commits created this iteration. It returns the
+// guardrail outcome, a reason code and an amend instruction list for backpressure.
+func enforceCommitPolicy(policy commitPolicyConfig, task planTask, headBefore, headAfter string) (bool, string, string) {
+	if !policy.enabled() || headBefore == headAfter {
+		return true, "", ""
+	}
+	commits, err := listCommits(headBefore, headAfter)
+	if err != nil {
+		// Fail-closed: commits cannot be pushed unchecked
+		return false, "git_error_commit_list", ""
+	}
+	violations, err := checkCommitPolicy(policy, task, commits)
+	if err != nil {
+		return false, "commit_policy_invalid", err.Error()
+	}
+	if len(violations) == 0 {
+		return true, "", ""
+	}
+	return false, "commit_policy:" + violations[0].Rule, formatCommitViolations(violations)
+}
+
+// unpushedBase returns where this run's unpushed commits start: the run's
+// starting HEAD, or the upstream once the run's earlier commits are pushed.
+// Commits that were already unpushed when the run started are not the run's
+// to fix and are never checked.
+func unpushedBase(runStartHead string) string {
+	upstream, err := gitOutput("rev-parse", "--verify", "--quiet", "@{upstream}")
+	if err != nil || upstream == "" {
+		return runStartHead
+	}
+	if runStartHead == "" {
+		return upstream
+	}
+	if _, err := gitOutput("merge-base", "--is-ancestor", runStartHead, upstream); err == nil {
+		return upstream
+	}
+	return runStartHead
+}
+
+// enforceUnpushedCommitPolicy checks the unpushed commits that precede this
+// iteration (base..headBefore). They were made for earlier tasks, so the task
+// ID rule is skipped; the other rules still apply.
+func enforceUnpushedCommitPolicy(policy commitPolicyConfig, base, headBefore string) (bool, string, string) {
+	if base == "" || headBefore == "" {
+		return true, "", ""
+	}
+	return enforceCommitPolicy(policy, planTask{}, base, headBefore)
+}
+
+func formatCommitViolations(violations []commitViolation) string {
+	lines := make([]string, 0, len(violations))
+	for _, v := range violations {
+		lines = append(lines, fmt.Sprintf("- %s %q: %s", v.Commit.shortHash(), v.Commit.subject(), v.Detail))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTaskIDFromTitle(t *testing.T) {
+	tests := map[string]string{
+		"T3: Enforce unique user email": "T3",
+		"AUTH-12: Add login":            "AUTH-12",
+		"T10) Add parser":               "T10",
+		"Add parser":                    "",
+	}
+	for title, want := range tests {
+		if got := taskIDFromTitle(title); got != want {
+			t.Errorf("taskIDFromTitle(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestCommitTrailers(t *testing.T) {
+	trailers := commitTrailers("feat: add parser\n\nBody text.\n\nRauf-Task: T3\nRauf-Spec: specs/parser.md")
+	if trailers["rauf-task"] != "T3" || trailers["rauf-spec"] != "specs/parser.md" {
+		t.Fatalf("unexpected trailers: %v", trailers)
+	}
+	if got := commitTrailers("feat: add parser\n\nJust a body: not trailers\nsecond line"); len(got) != 0 {
+		t.Fatalf("expected no trailers, got %v", got)
+	}
+}
+
+func TestCheckCommitPolicy(t *testing.T) {
+	policy := commitPolicyConfig{
+		MessagePattern:   `^(feat|fix|chore)(\(.+\))?: .+`,
+		RequireTaskID:    true,
+		ForbidFixup:      true,
+		AuthorEmail:      "agent@example.com",
+		RequiredTrailers: []string{"Rauf-Task"},
+	}
+	task := planTask{TitleLine: "T3: Enforce unique user email"}
+	good := commitInfo{
+		Hash:           "aaaaaaaaaaaa",
+		AuthorEmail:    "agent@example.com",
+		CommitterEmail: "Agent@Example.com",
+		Message:        "feat: enforce unique email (T3)\n\nRauf-Task: T3",
+	}
+	violations, err := checkCommitPolicy(policy, task, []commitInfo{good})
+	if err != nil || len(violations) != 0 {
+		t.Fatalf("expected no violations, got %v (err=%v)", violations, err)
+	}
+
+	bad := commitInfo{
+		Hash:           "bbbbbbbbbbbb",
+		AuthorEmail:    "someone@example.com",
+		CommitterEmail: "agent@example.com",
+		Message:        "fixup! enforce email",
+	}
+	violations, err = checkCommitPolicy(policy, task, []commitInfo{good, bad})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rules := []string{}
+	for _, v := range violations {
+		if v.Commit.Hash != bad.Hash {
+			t.Fatalf("unexpected violation on good commit: %+v", v)
+		}
+		rules = append(rules, v.Rule)
+	}
+	if strings.Join(rules, ",") != "fixup,message,task_id,identity,trailer" {
+		t.Fatalf("unexpected rules: %v", rules)
+	}
+
+	if _, err := checkCommitPolicy(commitPolicyConfig{MessagePattern: "("}, task, nil); err == nil {
+		t.Fatalf("expected invalid pattern error")
+	}
+
+	// Tasks without an ID skip the task reference check.
+	violations, _ = checkCommitPolicy(commitPolicyConfig{RequireTaskID: true}, planTask{TitleLine: "Add parser"}, []commitInfo{bad})
+	if len(violations) != 0 {
+		t.Fatalf("expected task id check to be skipped, got %v", violations)
+	}
+}
+
+func TestEnforceCommitPolicyGit(t *testing.T) {
+	repoDir := t.TempDir()
+	head := initGitRepo(t, repoDir)
+	chdirTemp(t, repoDir)
+
+	if err := os.WriteFile(filepath.Join(repoDir, "a.txt"), []byte("a\n"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-m", "feat: add a (T1)")
+	if err := os.WriteFile(filepath.Join(repoDir, "b.txt"), []byte("b\n"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-m", "WIP b")
+	after := runGit(t, repoDir, "rev-parse", "HEAD")
+
+	commits, err := listCommits(head, after)
+	if err != nil {
+		t.Fatalf("listCommits failed: %v", err)
+	}
+	if len(commits) != 2 || commits[0].subject() != "feat: add a (T1)" || commits[1].AuthorEmail != "test@example.com" {
+		t.Fatalf("unexpected commits: %+v", commits)
+	}
+
+	task := planTask{TitleLine: "T1: Add files"}
+	policy := commitPolicyConfig{ForbidFixup: true, RequireTaskID: true}
+	ok, reason, message := enforceCommitPolicy(policy, task, head, after)
+	if ok || reason != "commit_policy:fixup" {
+		t.Fatalf("expected commit_policy:fixup, got ok=%t reason=%s", ok, reason)
+	}
+	if !strings.Contains(message, `"WIP b"`) || !strings.Contains(message, "reference task T1") {
+		t.Fatalf("unexpected message: %q", message)
+	}
+
+	if ok, _, _ := enforceCommitPolicy(commitPolicyConfig{}, task, head, after); !ok {
+		t.Fatalf("expected disabled policy to pass")
+	}
+}
+
+func TestParseConfigCommitPolicy(t *testing.T) {
+	cfg := runtimeConfig{}
+	data := "commit_policy:\n  message_pattern: \"^(feat|fix): .+\"\n  require_task_id: true\n  forbid_fixup: true\n  author_email: agent@example.com\n  required_trailers: Rauf-Task, Rauf-Spec\nmax_commits_per_iteration: 1\n"
+	if err := parseConfigBytes([]byte(data), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	policy := cfg.CommitPolicy
+	if policy.MessagePattern != "^(feat|fix): .+" || !policy.RequireTaskID || !policy.ForbidFixup || policy.AuthorEmail != "agent@example.com" {
+		t.Fatalf("unexpected policy: %+v", policy)
+	}
+	if len(policy.RequiredTrailers) != 2 || policy.RequiredTrailers[1] != "Rauf-Spec" {
+		t.Fatalf("unexpected trailers: %v", policy.RequiredTrailers)
+	}
+	if cfg.MaxCommits != 1 {
+		t.Fatalf("expected section to end at top-level key, got max commits %d", cfg.MaxCommits)
+	}
+}
+
+func TestBackpressureCommitPolicy(t *testing.T) {
+	state := raufState{
+		PriorGuardrailStatus:  "fail",
+		PriorGuardrailReason:  "commit_policy:message",
+		PriorGuardrailMessage: `- abcd1234 "update stuff": subject does not match`,
+	}
+	pack := buildBackpressurePack(state, true)
+	if !strings.Contains(pack, "Amend or reword") || !strings.Contains(pack, "update stuff") {
+		t.Fatalf("expected commit policy backpressure, got:\n%s", pack)
+	}
+}
+
+func TestEnforceUnpushedCommitPolicy(t *testing.T) {
+	remote := t.TempDir()
+	runGit(t, remote, "init", "--bare")
+	repoDir := t.TempDir()
+	start := initGitRepo(t, repoDir)
+	chdirTemp(t, repoDir)
+
+	commit := func(name, msg string) string {
+		if err := os.WriteFile(filepath.Join(repoDir, name), []byte(name+"\n"), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		runGit(t, repoDir, "add", ".")
+		runGit(t, repoDir, "commit", "-m", msg)
+		return runGit(t, repoDir, "rev-parse", "HEAD")
+	}
+	policy := commitPolicyConfig{ForbidFixup: true, RequireTaskID: true}
+
+	// Without an upstream the run's start is the base.
+	if base := unpushedBase(start); base != start {
+		t.Fatalf("expected the fallback base, got %s", base)
+	}
+	commit("a.txt", "T1: add a")
+	// A WIP commit left behind by a blocked iteration...
+	wip := commit("b.txt", "WIP b")
+	ok, reason, message := enforceUnpushedCommitPolicy(policy, unpushedBase(start), wip)
+	if ok || reason != "commit_policy:fixup" || !strings.Contains(message, `"WIP b"`) {
+		t.Fatalf("expected the WIP commit to block the push, got ok=%t reason=%s message=%q", ok, reason, message)
+	}
+	if strings.Contains(message, "reference task") {
+		t.Fatalf("earlier commits must not be checked against the current task: %q", message)
+	}
+
+	// With an upstream, only commits after it are checked.
+	runGit(t, repoDir, "remote", "add", "origin", remote)
+	runGit(t, repoDir, "push", "-u", "origin", "HEAD")
+	clean := commit("c.txt", "T2: add c")
+	if base := unpushedBase(start); base != wip {
+		t.Fatalf("expected the upstream as base, got %s", base)
+	}
+	if ok, reason, _ := enforceUnpushedCommitPolicy(policy, unpushedBase(start), clean); !ok {
+		t.Fatalf("expected pushed commits to be skipped, got %s", reason)
+	}
+
+	// Unpushed commits made before the run started are left alone.
+	runStart := commit("d.txt", "WIP by hand")
+	runCommit := commit("e.txt", "T3: add e")
+	if base := unpushedBase(runStart); base != runStart {
+		t.Fatalf("expected the run's start as base, got %s", base)
+	}
+	if ok, reason, _ := enforceUnpushedCommitPolicy(policy, unpushedBase(runStart), runCommit); !ok {
+		t.Fatalf("expected commits from before the run to be skipped, got %s", reason)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// stoppedByUserReason is the exit reason for `rauf ctl stop` / SIGUSR2.
+const stoppedByUserReason = "stopped_by_user"
+
+// controlDir holds the control files a running loop checks between
+// iterations: `pause` blocks until removed, `stop` ends the run cleanly.
+var controlDir = filepath.Join(".rauf", "control")
+
+// controlPollInterval is how often a paused loop rechecks the control files.
+var controlPollInterval = time.Second
+
+func controlFile(name string) string {
+	return filepath.Join(controlDir, name)
+}
+
+func controlFileExists(name string) bool {
+	_, err := os.Stat(controlFile(name))
+	return err == nil
+}
+
+func writeControlFile(name string) error {
+	if err := os.MkdirAll(controlDir, 0o755); err != nil {
+		return err
+	}
+	stamp := time.Now().UTC().Format(time.RFC3339) + "\n"
+	return os.WriteFile(controlFile(name), []byte(stamp), 0o644)
+}
+
+func removeControlFile(name string) error {
+	if err := os.Remove(controlFile(name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// runCtl implements `rauf ctl pause|resume|stop`.
+func runCtl(action string) error {
+	switch action {
+	case "pause":
+		if err := writeControlFile("pause"); err != nil {
+			return err
+		}
+		fmt.Println("Pause requested; the loop pauses before its next iteration.")
+	case "resume":
+		if err := removeControlFile("pause"); err != nil {
+			return err
+		}
+		fmt.Println("Resumed.")
+	case "stop":
+		if err := writeControlFile("stop"); err != nil {
+			return err
+		}
+		fmt.Println("Stop requested; the loop exits after the current iteration.")
+	default:
+		return fmt.Errorf("unknown ctl action: %q (expected pause, resume or stop)", action)
+	}
+	return nil
+}
+
+// toggleControlPause flips the pause file; used by SIGUSR1.
+func toggleControlPause() {
+	var err error
+	if controlFileExists("pause") {
+		err = removeControlFile("pause")
+	} else {
+		err = writeControlFile("pause")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to toggle pause: %v\n", err)
+	}
+}
+
+// waitForLoopControl is called between iterations. It returns an exit reason
+// when the loop should stop, and blocks while the loop is paused.
+func waitForLoopControl(ctx context.Context) (string, error) {
+	paused := false
+	for {
+		if controlFileExists("stop") {
+			if err := removeControlFile("stop"); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to clear stop request: %v\n", err)
+			}
+			fmt.Println("Stop requested; exiting.")
+			return stoppedByUserReason, nil
+		}
+		if !controlFileExists("pause") {
+			if paused {
+				fmt.Println("Resuming.")
+			}
+			return "", nil
+		}
+		if interruptRequested(ctx) {
+			return interruptExitReason, nil
+		}
+		if !paused {
+			paused = true
+			fmt.Println("Paused. Run `rauf ctl resume` (or send SIGUSR1) to continue, `rauf ctl stop` to exit.")
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(controlPollInterval):
+		}
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchControlSignals maps SIGUSR1 to pause/resume and SIGUSR2 to stop by
+// writing the same control files as `rauf ctl`.
+func watchControlSignals() func() {
+	signals := make(chan os.Signal, 2)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-signals:
+				if sig == syscall.SIGUSR1 {
+					toggleControlPause()
+				} else if err := writeControlFile("stop"); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: failed to request stop: %v\n", err)
+				}
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
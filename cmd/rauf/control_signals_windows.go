@@ -0,0 +1,8 @@
+//go:build windows
+
+package main
+
+// watchControlSignals is a no-op on Windows; use `rauf ctl` instead.
+func watchControlSignals() func() {
+	return func() {}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRunCtl(t *testing.T) {
+	chdirTemp(t, t.TempDir())
+
+	if err := runCtl("pause"); err != nil || !controlFileExists("pause") {
+		t.Fatalf("expected pause file, err=%v", err)
+	}
+	if err := runCtl("resume"); err != nil || controlFileExists("pause") {
+		t.Fatalf("expected pause file removed, err=%v", err)
+	}
+	if err := runCtl("resume"); err != nil {
+		t.Fatalf("resume without pause should succeed: %v", err)
+	}
+	if err := runCtl("stop"); err != nil || !controlFileExists("stop") {
+		t.Fatalf("expected stop file, err=%v", err)
+	}
+	if err := runCtl("restart"); err == nil {
+		t.Fatalf("expected error for unknown action")
+	}
+}
+
+func TestWaitForLoopControl(t *testing.T) {
+	chdirTemp(t, t.TempDir())
+	orig := controlPollInterval
+	controlPollInterval = 10 * time.Millisecond
+	defer func() { controlPollInterval = orig }()
+
+	if reason, err := waitForLoopControl(context.Background()); reason != "" || err != nil {
+		t.Fatalf("expected no-op without control files, got %q %v", reason, err)
+	}
+
+	if err := writeControlFile("pause"); err != nil {
+		t.Fatalf("pause failed: %v", err)
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = removeControlFile("pause")
+	}()
+	start := time.Now()
+	if reason, err := waitForLoopControl(context.Background()); reason != "" || err != nil {
+		t.Fatalf("expected resume, got %q %v", reason, err)
+	}
+	if time.Since(start) < 40*time.Millisecond {
+		t.Fatalf("expected loop to block while paused")
+	}
+
+	if err := writeControlFile("pause"); err != nil {
+		t.Fatalf("pause failed: %v", err)
+	}
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		_ = writeControlFile("stop")
+	}()
+	if reason, err := waitForLoopControl(context.Background()); reason != stoppedByUserReason || err != nil {
+		t.Fatalf("expected stop while paused, got %q %v", reason, err)
+	}
+	if controlFileExists("stop") {
+		t.Fatalf("stop request should be consumed")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := waitForLoopControl(ctx); err == nil {
+		t.Fatalf("expected context error while paused")
+	}
+}
+
+func TestRunModeStopsOnControlFile(t *testing.T) {
+	chdirTemp(t, t.TempDir())
+	if err := os.WriteFile("PROMPT_plan.md", []byte("plan"), 0o644); err != nil {
+		t.Fatalf("write prompt failed: %v", err)
+	}
+	if err := writeControlFile("stop"); err != nil {
+		t.Fatalf("stop failed: %v", err)
+	}
+
+	orig := runHarnessOnce
+	defer func() { runHarnessOnce = orig }()
+	runHarnessOnce = func(ctx context.Context, prompt string, harness, harnessArgs string, logFile *os.File, runner runtimeExec) (string, error) {
+		t.Fatalf("harness must not run after a stop request")
+		return "", nil
+	}
+
+	cfg := modeConfig{mode: "plan", promptFile: "PROMPT_plan.md", maxIterations: 5}
+	report := &RunReport{}
+	res, err := runMode(context.Background(), cfg, runtimeConfig{}, runtimeExec{Quiet: true}, raufState{}, false, "", "IMPLEMENTATION_PLAN.md", "harness", "", true, "logs", false, 0, 0, 0, false, nil, 0, nil, nil, report)
+	if err != nil || res.ExitReason != stoppedByUserReason {
+		t.Fatalf("expected stopped_by_user, got %q err=%v", res.ExitReason, err)
+	}
+	if len(report.Iterations) != 1 || report.Iterations[0].ExitReason != stoppedByUserReason {
+		t.Fatalf("unexpected iteration stats: %+v", report.Iterations)
+	}
+}
+
+func TestParseArgsCtl(t *testing.T) {
+	cfg, err := parseArgs([]string{"ctl", "pause"})
+	if err != nil || cfg.mode != "ctl" || cfg.ctlAction != "pause" {
+		t.Fatalf("unexpected ctl parse: %+v err=%v", cfg, err)
+	}
+	if _, err := parseArgs([]string{"ctl"}); err == nil {
+		t.Fatalf("expected error without action")
+	}
+}
+
+func TestRunMainKeepsPendingStopForNonLoopCommands(t *testing.T) {
+	chdirTemp(t, t.TempDir())
+	if err := runCtl("stop"); err != nil {
+		t.Fatalf("stop failed: %v", err)
+	}
+	for _, args := range [][]string{{"ctl", "pause"}, {"ctl", "resume"}, {"version"}} {
+		if code := runMain(args); code != 0 {
+			t.Fatalf("runMain(%v) = %d", args, code)
+		}
+		if !controlFileExists("stop") {
+			t.Fatalf("runMain(%v) cleared a stop meant for a running loop", args)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// dependencyChange describes a single dependency added, removed or re-versioned
+// in a manifest during an iteration.
+type dependencyChange struct {
+	Manifest string `json:"manifest"`
+	Name     string `json:"name"`
+	Action   string `json:"action"` // added | removed | bumped
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+}
+
+// dependencyDelta is the parsed dependency impact of an iteration diff.
+// Manifests lists every manifest or lockfile that was touched, including
+// formats rauf does not parse.
+type dependencyDelta struct {
+	Manifests []string           `json:"manifests,omitempty"`
+	Changes   []dependencyChange `json:"changes,omitempty"`
+}
+
+func (d dependencyDelta) empty() bool {
+	return len(d.Manifests) == 0 && len(d.Changes) == 0
+}
+
+// parsedManifests are manifests whose dependency lists rauf understands.
+var parsedManifests = map[string]func(string) map[string]string{
+	"go.mod":       parseGoModDeps,
+	"package.json": parsePackageJSONDeps,
+}
+
+// opaqueManifests are manifests and lockfiles that are detected but not parsed.
+var opaqueManifests = map[string]struct{}{
+	"go.sum":              {},
+	"package-lock.json":   {},
+	"npm-shrinkwrap.json": {},
+	"yarn.lock":           {},
+	"pnpm-lock.yaml":      {},
+	"Pipfile":             {},
+	"Pipfile.lock":        {},
+	"poetry.lock":         {},
+	"pyproject.toml":      {},
+	"Cargo.toml":          {},
+	"Cargo.lock":          {},
+	"Gemfile":             {},
+	"Gemfile.lock":        {},
+	"composer.json":       {},
+	"composer.lock":       {},
+}
+
+var requirementsFile = regexp.MustCompile(`^requirements([-_.][A-Za-z0-9_.-]+)?\.txt$`)
+
+func isDependencyManifest(path string) bool {
+	base := filepath.Base(path)
+	if _, ok := parsedManifests[base]; ok {
+		return true
+	}
+	if _, ok := opaqueManifests[base]; ok {
+		return true
+	}
+	return requirementsFile.MatchString(base)
+}
+
+func manifestParser(path string) func(string) map[string]string {
+	base := filepath.Base(path)
+	if parse, ok := parsedManifests[base]; ok {
+		return parse
+	}
+	if requirementsFile.MatchString(base) {
+		return parseRequirementsDeps
+	}
+	return nil
+}
+
+// detectDependencyDelta inspects manifests changed between headBefore and headAfter
+// (or in the working tree when HEAD did not move) and diffs their dependencies.
+func detectDependencyDelta(headBefore, headAfter string) (dependencyDelta, error) {
+	delta := dependencyDelta{}
+	files, gitErr := listChangedFiles(headBefore, headAfter)
+	if gitErr {
+		return delta, fmt.Errorf("unable to list changed files")
+	}
+	for _, file := range files {
+		if !isDependencyManifest(file) {
+			continue
+		}
+		delta.Manifests = append(delta.Manifests, file)
+		parse := manifestParser(file)
+		if parse == nil {
+			continue
+		}
+		before := readFileAtRevision(headBefore, file)
+		after := ""
+		if headAfter != headBefore {
+			after = readFileAtRevision(headAfter, file)
+		} else if data, err := os.ReadFile(file); err == nil {
+			after = string(data)
+		}
+		delta.Changes = append(delta.Changes, diffDependencies(file, parse(before), parse(after))...)
+	}
+	return delta, nil
+}
+
+func readFileAtRevision(rev, path string) string {
+	if rev == "" {
+		return ""
+	}
+	content, err := gitStdout("show", rev+":"+filepath.ToSlash(path))
+	if err != nil {
+		return ""
+	}
+	return content
+}
+
+func diffDependencies(manifest string, before, after map[string]string) []dependencyChange {
+	names := make([]string, 0, len(before)+len(after))
+	seen := make(map[string]struct{}, len(before)+len(after))
+	for name := range before {
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := seen[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []dependencyChange{}
+	for _, name := range names {
+		from, hadBefore := before[name]
+		to, hasAfter := after[name]
+		switch {
+		case !hadBefore && hasAfter:
+			changes = append(changes, dependencyChange{Manifest: manifest, Name: name, Action: "added", To: to})
+		case hadBefore && !hasAfter:
+			changes = append(changes, dependencyChange{Manifest: manifest, Name: name, Action: "removed", From: from})
+		case from != to:
+			changes = append(changes, dependencyChange{Manifest: manifest, Name: name, Action: "bumped", From: from, To: to})
+		}
+	}
+	return changes
+}
+
+func parseGoModDeps(content string) map[string]string {
+	deps := map[string]string{}
+	inRequire := false
+	for _, line := range strings.Split(content, "\n") {
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if inRequire {
+			if trimmed == ")" {
+				inRequire = false
+				continue
+			}
+			addGoModRequire(deps, trimmed)
+			continue
+		}
+		if trimmed == "require (" {
+			inRequire = true
+			continue
+		}
+		if strings.HasPrefix(trimmed, "require ") {
+			addGoModRequire(deps, strings.TrimSpace(strings.TrimPrefix(trimmed, "require ")))
+		}
+	}
+	return deps
+}
+
+func addGoModRequire(deps map[string]string, spec string) {
+	fields := strings.Fields(spec)
+	if len(fields) < 2 {
+		return
+	}
+	deps[fields[0]] = fields[1]
+}
+
+func parsePackageJSONDeps(content string) map[string]string {
+	deps := map[string]string{}
+	if strings.TrimSpace(content) == "" {
+		return deps
+	}
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		return deps
+	}
+	for _, section := range []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"} {
+		raw, ok := manifest[section]
+		if !ok {
+			continue
+		}
+		var entries map[string]string
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			continue
+		}
+		for name, version := range entries {
+			deps[name] = version
+		}
+	}
+	return deps
+}
+
+func parseRequirementsDeps(content string) map[string]string {
+	deps := map[string]string{}
+	for _, line := range strings.Split(content, "\n") {
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "-") {
+			continue
+		}
+		end := strings.IndexAny(trimmed, "=<>!~;[ @")
+		name := trimmed
+		spec := ""
+		if end >= 0 {
+			name = trimmed[:end]
+			spec = strings.TrimSpace(trimmed[end:])
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		deps[name] = spec
+	}
+	return deps
+}
+
+func normalizeDependencyPolicy(cfg runtimeConfig) string {
+	policy := strings.ToLower(strings.TrimSpace(cfg.DependencyPolicy))
+	switch policy {
+	case "allow", "deny", "allowlist", "require_task_mention":
+		return policy
+	default:
+		return "allow"
+	}
+}
+
+// enforceDependencyGuardrail applies dependency_policy to a parsed delta.
+// Removals are always allowed except under "deny"; lockfile-only edits are
+// only blocked by "deny".
+func enforceDependencyGuardrail(cfg runtimeConfig, task planTask, delta dependencyDelta) (bool, string) {
+	if delta.empty() {
+		return true, ""
+	}
+	switch normalizeDependencyPolicy(cfg) {
+	case "deny":
+		if len(delta.Changes) > 0 {
+			return false, "dependency_denied:" + delta.Changes[0].Name
+		}
+		return false, "dependency_manifest_changed:" + delta.Manifests[0]
+	case "allowlist":
+		for _, change := range delta.Changes {
+			if change.Action == "removed" {
+				continue
+			}
+			if !dependencyAllowlisted(cfg.DependencyAllowlist, change.Name) {
+				return false, "dependency_not_allowlisted:" + change.Name
+			}
+		}
+	case "require_task_mention":
+		taskText := strings.ToLower(strings.Join(task.TaskBlock, "\n"))
+		for _, change := range delta.Changes {
+			if change.Action == "removed" {
+				continue
+			}
+			if !strings.Contains(taskText, strings.ToLower(change.Name)) {
+				return false, "dependency_not_in_task:" + change.Name
+			}
+		}
+	}
+	return true, ""
+}
+
+// dependencyAllowlisted matches exact names or path-style prefixes
+// (e.g. "github.com/acme/" or "@types/").
+func dependencyAllowlisted(allowlist []string, name string) bool {
+	for _, allowed := range allowlist {
+		allowed = strings.TrimSpace(allowed)
+		if allowed == "" {
+			continue
+		}
+		if strings.EqualFold(allowed, name) {
+			return true
+		}
+		if strings.HasSuffix(allowed, "/") && strings.HasPrefix(name, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatDependencyChange(change dependencyChange) string {
+	switch change.Action {
+	case "added":
+		return fmt.Sprintf("+ %s %s (%s)", change.Name, change.To, change.Manifest)
+	case "removed":
+		return fmt.Sprintf("- %s %s (%s)", change.Name, change.From, change.Manifest)
+	default:
+		return fmt.Sprintf("~ %s %s -> %s (%s)", change.Name, change.From, change.To, change.Manifest)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseGoModDeps(t *testing.T) {
+	content := `module example.com/app
+
+go 1.21
+
+require github.com/single/dep v1.0.0
+
+require (
+	github.com/a/b v1.2.3
+	golang.org/x/text v0.14.0 // indirect
+)
+`
+	got := parseGoModDeps(content)
+	want := map[string]string{
+		"github.com/single/dep": "v1.0.0",
+		"github.com/a/b":        "v1.2.3",
+		"golang.org/x/text":     "v0.14.0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseGoModDeps() = %v, want %v", got, want)
+	}
+}
+
+func TestParsePackageJSONDeps(t *testing.T) {
+	content := `{"name": "app", "version": "1.0.0", "dependencies": {"lodash": "^4.17.21"}, "devDependencies": {"jest": "29.0.0"}}`
+	got := parsePackageJSONDeps(content)
+	want := map[string]string{"lodash": "^4.17.21", "jest": "29.0.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parsePackageJSONDeps() = %v, want %v", got, want)
+	}
+	if len(parsePackageJSONDeps("not json")) != 0 {
+		t.Fatalf("expected invalid JSON to yield no deps")
+	}
+}
+
+func TestParseRequirementsDeps(t *testing.T) {
+	content := "# comment\nRequests==2.31.0\nflask>=2.0 # web\n-r other.txt\nnumpy\n"
+	got := parseRequirementsDeps(content)
+	want := map[string]string{"requests": "==2.31.0", "flask": ">=2.0", "numpy": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseRequirementsDeps() = %v, want %v", got, want)
+	}
+}
+
+func TestDiffDependencies(t *testing.T) {
+	before := map[string]string{"a": "1", "b": "1", "c": "1"}
+	after := map[string]string{"a": "1", "b": "2", "d": "1"}
+	got := diffDependencies("go.mod", before, after)
+	want := []dependencyChange{
+		{Manifest: "go.mod", Name: "b", Action: "bumped", From: "1", To: "2"},
+		{Manifest: "go.mod", Name: "c", Action: "removed", From: "1"},
+		{Manifest: "go.mod", Name: "d", Action: "added", To: "1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("diffDependencies() = %+v, want %+v", got, want)
+	}
+}
+
+func TestIsDependencyManifest(t *testing.T) {
+	for _, path := range []string{"go.mod", "web/package.json", "yarn.lock", "requirements-dev.txt", "requirements.txt"} {
+		if !isDependencyManifest(path) {
+			t.Errorf("expected %s to be a manifest", path)
+		}
+	}
+	for _, path := range []string{"main.go", "docs/requirements.md", "package.jsonx"} {
+		if isDependencyManifest(path) {
+			t.Errorf("expected %s not to be a manifest", path)
+		}
+	}
+}
+
+func TestDetectDependencyDelta(t *testing.T) {
+	repoDir := t.TempDir()
+	initGitRepo(t, repoDir)
+	chdirTemp(t, repoDir)
+
+	if err := os.WriteFile(filepath.Join(repoDir, "go.mod"), []byte("module x\n\nrequire github.com/a/b v1.0.0\n"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-m", "add go.mod")
+	headBefore := runGit(t, repoDir, "rev-parse", "HEAD")
+
+	if err := os.WriteFile(filepath.Join(repoDir, "go.mod"), []byte("module x\n\nrequire (\n\tgithub.com/a/b v1.1.0\n\tgithub.com/c/d v0.1.0\n)\n"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "go.sum"), []byte("sum\n"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	t.Run("working tree", func(t *testing.T) {
+		delta, err := detectDependencyDelta(headBefore, headBefore)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(delta.Manifests) != 2 {
+			t.Fatalf("expected 2 manifests, got %v", delta.Manifests)
+		}
+		if len(delta.Changes) != 2 {
+			t.Fatalf("expected 2 changes, got %+v", delta.Changes)
+		}
+	})
+
+	t.Run("committed", func(t *testing.T) {
+		runGit(t, repoDir, "add", ".")
+		runGit(t, repoDir, "commit", "-m", "bump")
+		headAfter := runGit(t, repoDir, "rev-parse", "HEAD")
+		delta, err := detectDependencyDelta(headBefore, headAfter)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []dependencyChange{
+			{Manifest: "go.mod", Name: "github.com/a/b", Action: "bumped", From: "v1.0.0", To: "v1.1.0"},
+			{Manifest: "go.mod", Name: "github.com/c/d", Action: "added", To: "v0.1.0"},
+		}
+		if !reflect.DeepEqual(delta.Changes, want) {
+			t.Fatalf("changes = %+v, want %+v", delta.Changes, want)
+		}
+	})
+}
+
+func TestReadFileAtRevisionIgnoresGitWarnings(t *testing.T) {
+	repoDir := t.TempDir()
+	initGitRepo(t, repoDir)
+	chdirTemp(t, repoDir)
+	content := "module x\n\nrequire github.com/a/b v1.0.0\n"
+	if err := os.WriteFile(filepath.Join(repoDir, "go.mod"), []byte(content), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-m", "add go.mod")
+	head := runGit(t, repoDir, "rev-parse", "HEAD")
+
+	// Tracing makes every git command write to stderr.
+	t.Setenv("GIT_TRACE", "1")
+	if got := readFileAtRevision(head, "go.mod"); got != content {
+		t.Fatalf("expected the blob only, got %q", got)
+	}
+}
+
+func TestEnforceDependencyGuardrail(t *testing.T) {
+	added := dependencyDelta{
+		Manifests: []string{"go.mod"},
+		Changes:   []dependencyChange{{Manifest: "go.mod", Name: "github.com/acme/lib", Action: "added", To: "v1.0.0"}},
+	}
+	removed := dependencyDelta{
+		Manifests: []string{"go.mod"},
+		Changes:   []dependencyChange{{Manifest: "go.mod", Name: "github.com/old/lib", Action: "removed", From: "v1.0.0"}},
+	}
+	lockOnly := dependencyDelta{Manifests: []string{"go.sum"}}
+	task := planTask{TaskBlock: []string{"- [ ] T1: add github.com/acme/lib client"}}
+
+	tests := []struct {
+		name   string
+		cfg    runtimeConfig
+		task   planTask
+		delta  dependencyDelta
+		ok     bool
+		reason string
+	}{
+		{"allow passes", runtimeConfig{DependencyPolicy: "allow"}, planTask{}, added, true, ""},
+		{"empty delta passes deny", runtimeConfig{DependencyPolicy: "deny"}, planTask{}, dependencyDelta{}, true, ""},
+		{"deny blocks add", runtimeConfig{DependencyPolicy: "deny"}, planTask{}, added, false, "dependency_denied:github.com/acme/lib"},
+		{"deny blocks lockfile", runtimeConfig{DependencyPolicy: "deny"}, planTask{}, lockOnly, false, "dependency_manifest_changed:go.sum"},
+		{"allowlist exact", runtimeConfig{DependencyPolicy: "allowlist", DependencyAllowlist: []string{"github.com/acme/lib"}}, planTask{}, added, true, ""},
+		{"allowlist prefix", runtimeConfig{DependencyPolicy: "allowlist", DependencyAllowlist: []string{"github.com/acme/"}}, planTask{}, added, true, ""},
+		{"allowlist blocks", runtimeConfig{DependencyPolicy: "allowlist", DependencyAllowlist: []string{"github.com/other/"}}, planTask{}, added, false, "dependency_not_allowlisted:github.com/acme/lib"},
+		{"allowlist permits removal", runtimeConfig{DependencyPolicy: "allowlist"}, planTask{}, removed, true, ""},
+		{"allowlist permits lockfile", runtimeConfig{DependencyPolicy: "allowlist"}, planTask{}, lockOnly, true, ""},
+		{"task mention passes", runtimeConfig{DependencyPolicy: "require_task_mention"}, task, added, true, ""},
+		{"task mention blocks", runtimeConfig{DependencyPolicy: "require_task_mention"}, planTask{TaskBlock: []string{"- [ ] T1: other"}}, added, false, "dependency_not_in_task:github.com/acme/lib"},
+		{"unknown policy allows", runtimeConfig{DependencyPolicy: "bogus"}, planTask{}, added, true, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ok, reason := enforceDependencyGuardrail(tc.cfg, tc.task, tc.delta)
+			if ok != tc.ok || reason != tc.reason {
+				t.Fatalf("enforceDependencyGuardrail() = (%t, %q), want (%t, %q)", ok, reason, tc.ok, tc.reason)
+			}
+		})
+	}
+}
+
+func TestParseConfigDependencyPolicy(t *testing.T) {
+	cfg := runtimeConfig{}
+	data := "dependency_policy: allowlist\ndependency_allowlist:\n  - github.com/acme/\n  - \"lodash\"\n"
+	if err := parseConfigBytes([]byte(data), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if cfg.DependencyPolicy != "allowlist" {
+		t.Fatalf("expected allowlist policy, got %q", cfg.DependencyPolicy)
+	}
+	if !reflect.DeepEqual(cfg.DependencyAllowlist, []string{"github.com/acme/", "lodash"}) {
+		t.Fatalf("unexpected allowlist: %v", cfg.DependencyAllowlist)
+	}
+}
@@ -15,6 +15,16 @@ type escalationConfig struct {
 	MaxEscalations         int // Maximum number of escalations per run
 }
 
+// modelTier is one rung of the model ladder. Zero thresholds and cooldown
+// inherit the model_escalation values.
+type modelTier struct {
+	Name                   string
+	ConsecutiveVerifyFails int // Trigger: climb from this rung after N consecutive verify failures
+	NoProgressIters        int // Trigger: climb from this rung after N no-progress iterations
+	GuardrailFailures      int // Trigger: climb from this rung after N consecutive guardrail failures
+	CooldownIters          int // Minimum iterations to stay on this rung after moving to it
+}
+
 // defaultEscalationConfig returns the default (disabled) escalation config.
 func defaultEscalationConfig() escalationConfig {
 	return escalationConfig{
@@ -27,29 +37,96 @@ func defaultEscalationConfig() escalationConfig {
 	}
 }
 
-// shouldEscalateModel determines if we should switch to the stronger model.
+// modelLadder returns the ordered model tiers, weakest first. Without a
+// `models:` list, model_default and model_strong form a two-rung ladder.
+func modelLadder(cfg runtimeConfig) []modelTier {
+	if len(cfg.Models) > 0 {
+		return cfg.Models
+	}
+	if cfg.ModelStrong == "" {
+		return []modelTier{{Name: cfg.ModelDefault}}
+	}
+	return []modelTier{{Name: cfg.ModelDefault}, {Name: cfg.ModelStrong}}
+}
+
+func ladderIndex(ladder []modelTier, model string) (int, bool) {
+	for i, tier := range ladder {
+		if model != "" && tier.Name == model {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// baseModelTier is the rung escalation starts from: the rung of the base
+// model (ModelDefault, possibly chosen per mode or task), or 0 when the base
+// model is not on the ladder.
+func baseModelTier(cfg runtimeConfig, ladder []modelTier) int {
+	tier, _ := ladderIndex(ladder, cfg.ModelDefault)
+	return tier
+}
+
+// currentModelTier returns the ladder index of the model in use, never
+// below the base tier. An empty CurrentModel means the base model.
+func currentModelTier(state raufState, cfg runtimeConfig, ladder []modelTier) int {
+	base := baseModelTier(cfg, ladder)
+	if tier, ok := ladderIndex(ladder, state.CurrentModel); ok && tier > base {
+		return tier
+	}
+	return base
+}
+
+// tierModel returns the model name for a rung; the base tier is the base
+// model.
+func tierModel(cfg runtimeConfig, ladder []modelTier, tier int) string {
+	if tier == baseModelTier(cfg, ladder) && cfg.ModelDefault != "" {
+		return cfg.ModelDefault
+	}
+	return ladder[tier].Name
+}
+
+func tierThreshold(value, fallback int) int {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func tierCooldown(cfg runtimeConfig, tier modelTier) int {
+	return tierThreshold(tier.CooldownIters, cfg.ModelEscalation.CooldownIters)
+}
+
+// tierTrigger returns the escalation trigger met on a rung, or "".
+func tierTrigger(state raufState, cfg runtimeConfig, rung modelTier) string {
+	if limit := tierThreshold(rung.ConsecutiveVerifyFails, cfg.ModelEscalation.ConsecutiveVerifyFails); limit > 0 &&
+		state.ConsecutiveVerifyFails >= limit {
+		return "consecutive_verify_fails"
+	}
+	if limit := tierThreshold(rung.NoProgressIters, cfg.ModelEscalation.NoProgressIters); limit > 0 &&
+		state.NoProgressStreak >= limit {
+		return "no_progress_iters"
+	}
+	if limit := tierThreshold(rung.GuardrailFailures, cfg.ModelEscalation.GuardrailFailures); limit > 0 &&
+		state.ConsecutiveGuardrailFails >= limit {
+		return "guardrail_failures"
+	}
+	return ""
+}
+
+// shouldEscalateModel determines if we should climb to the next model tier.
 // Returns (shouldEscalate, triggerReason, suppressionReason).
 func shouldEscalateModel(state raufState, cfg runtimeConfig) (bool, string, string) {
 	if !cfg.ModelEscalation.Enabled {
 		return false, "", ""
 	}
-	if cfg.ModelStrong == "" {
+	ladder := modelLadder(cfg)
+	if len(ladder) < 2 {
 		return false, "", ""
 	}
+	tier := currentModelTier(state, cfg, ladder)
 
 	// Check triggers first to see if we WOULD escalate
-	triggerReason := ""
-	if cfg.ModelEscalation.ConsecutiveVerifyFails > 0 &&
-		state.ConsecutiveVerifyFails >= cfg.ModelEscalation.ConsecutiveVerifyFails {
-		triggerReason = "consecutive_verify_fails"
-	} else if cfg.ModelEscalation.NoProgressIters > 0 &&
-		state.NoProgressStreak >= cfg.ModelEscalation.NoProgressIters {
-		triggerReason = "no_progress_iters"
-	} else if cfg.ModelEscalation.GuardrailFailures > 0 &&
-		state.ConsecutiveGuardrailFails >= cfg.ModelEscalation.GuardrailFailures {
-		triggerReason = "guardrail_failures"
-	}
-
+	triggerReason := tierTrigger(state, cfg, ladder[tier])
 	if triggerReason == "" {
 		return false, "", ""
 	}
@@ -59,34 +136,107 @@ func shouldEscalateModel(state raufState, cfg runtimeConfig) (bool, string, stri
 		return false, triggerReason, "max_escalations_reached"
 	}
 	// Already escalated and still in minimum duration
-	if state.CurrentModel == cfg.ModelStrong && state.MinStrongIterationsRemaining > 0 {
+	if tier > 0 && state.MinStrongIterationsRemaining > 0 {
 		return false, triggerReason, "min_strong_iterations_active"
 	}
+	// The legacy default/strong pair reports the trigger on the strong model
+	// and simply stays there, as it always has.
+	if tier == len(ladder)-1 && !legacyModelLadder(cfg) {
+		return false, triggerReason, "top_tier_reached"
+	}
 
 	return true, triggerReason, ""
 }
 
-// shouldDeescalateModel determines if we should return to the default model.
+// legacyModelLadder reports whether the ladder is the model_default /
+// model_strong pair rather than a `models:` list.
+func legacyModelLadder(cfg runtimeConfig) bool {
+	return len(cfg.Models) == 0
+}
+
+// shouldDeescalateModel determines if we should drop back one model tier.
 func shouldDeescalateModel(state raufState, cfg runtimeConfig) bool {
 	if !cfg.ModelEscalation.Enabled {
 		return false
 	}
-	if state.CurrentModel != cfg.ModelStrong {
+	ladder := modelLadder(cfg)
+	tier := currentModelTier(state, cfg, ladder)
+	if tier == baseModelTier(cfg, ladder) {
+		return false
+	}
+	if state.MinStrongIterationsRemaining > 0 {
 		return false
 	}
-	// De-escalate if minimum duration has expired
-	return state.MinStrongIterationsRemaining <= 0
+	// De-escalate once the minimum duration has expired. On a `models:`
+	// ladder, stay unless the lower rung would climb straight back.
+	return legacyModelLadder(cfg) || tierTrigger(state, cfg, ladder[tier-1]) == ""
+}
+
+// escalateModel climbs one rung and returns the escalation event.
+func escalateModel(state *raufState, cfg runtimeConfig, reason string) escalationEvent {
+	ladder := modelLadder(cfg)
+	from := currentModelTier(*state, cfg, ladder)
+	to := from + 1
+	cooldown := tierCooldown(cfg, ladder[to])
+	event := escalationEvent{
+		Type:      "escalated",
+		FromModel: tierModel(cfg, ladder, from),
+		ToModel:   tierModel(cfg, ladder, to),
+		FromTier:  from,
+		ToTier:    to,
+		Reason:    reason,
+		Cooldown:  cooldown,
+	}
+	state.CurrentModel = event.ToModel
+	state.EscalationCount++
+	state.MinStrongIterationsRemaining = cooldown
+	if !legacyModelLadder(cfg) {
+		// On a multi-tier ladder, stay at least one iteration so the new tier
+		// gets a turn before the next climb. The legacy default/strong pair
+		// keeps its configured cooldown, including 0.
+		state.MinStrongIterationsRemaining = max(cooldown, 1)
+	}
+	state.LastEscalationReason = reason
+	return event
+}
+
+// deescalateModel drops back one rung and returns the event.
+func deescalateModel(state *raufState, cfg runtimeConfig) escalationEvent {
+	ladder := modelLadder(cfg)
+	from := currentModelTier(*state, cfg, ladder)
+	to := from - 1
+	event := escalationEvent{
+		Type:      "de_escalated",
+		FromModel: state.CurrentModel,
+		ToModel:   tierModel(cfg, ladder, to),
+		FromTier:  from,
+		ToTier:    to,
+		Reason:    "min_strong_iterations_expired",
+	}
+	state.CurrentModel = event.ToModel
+	if to > baseModelTier(cfg, ladder) {
+		// Give each rung its cooldown on the way down too.
+		state.MinStrongIterationsRemaining = tierCooldown(cfg, ladder[to])
+		event.Cooldown = state.MinStrongIterationsRemaining
+	} else {
+		// Back on the base model, which may differ per task.
+		state.CurrentModel = ""
+		state.LastEscalationReason = ""
+	}
+	return event
 }
 
 // computeEffectiveModel returns the model to use for this iteration.
 func computeEffectiveModel(state raufState, cfg runtimeConfig) string {
-	if !cfg.ModelEscalation.Enabled {
+	if !cfg.ModelEscalation.Enabled || state.CurrentModel == "" {
 		return cfg.ModelDefault
 	}
-	if state.CurrentModel != "" {
-		return state.CurrentModel
+	// A task whose base tier is above the escalated model runs on its base.
+	ladder := modelLadder(cfg)
+	if tier, ok := ladderIndex(ladder, state.CurrentModel); ok && tier <= baseModelTier(cfg, ladder) {
+		return cfg.ModelDefault
 	}
-	return cfg.ModelDefault
+	return state.CurrentModel
 }
 
 // applyModelChoice injects the model flag into harness args.
@@ -222,10 +372,29 @@ type escalationEvent struct {
 	Type      string // "escalated", "de_escalated", "suppressed", "none"
 	FromModel string
 	ToModel   string
+	FromTier  int
+	ToTier    int
 	Reason    string
 	Cooldown  int
 }
 
+// suppressedEscalation describes a climb that was blocked: we wanted to go
+// one rung up but couldn't.
+func suppressedEscalation(state raufState, cfg runtimeConfig, reason string) escalationEvent {
+	ladder := modelLadder(cfg)
+	from := currentModelTier(state, cfg, ladder)
+	to := min(from+1, len(ladder)-1)
+	return escalationEvent{
+		Type:      "suppressed",
+		FromModel: tierModel(cfg, ladder, from),
+		ToModel:   tierModel(cfg, ladder, to),
+		FromTier:  from,
+		ToTier:    to,
+		Reason:    reason,
+		Cooldown:  state.MinStrongIterationsRemaining,
+	}
+}
+
 // updateModelEscalationState handles model switching logic if enabled.
 // It assumes failure counters in state have already been updated by updateBackpressureState.
 func updateModelEscalationState(state raufState, cfg runtimeConfig) (raufState, escalationEvent) {
@@ -239,45 +408,38 @@ func updateModelEscalationState(state raufState, cfg runtimeConfig) (raufState,
 		state.MinStrongIterationsRemaining--
 	}
 
-	// Check for escalation
 	// Check for escalation
 	shouldEscalate, triggerReason, suppressed := shouldEscalateModel(state, cfg)
 	if shouldEscalate {
-		if state.CurrentModel != cfg.ModelStrong {
-			event.Type = "escalated"
-			event.FromModel = state.CurrentModel
-			if event.FromModel == "" {
-				event.FromModel = cfg.ModelDefault
-			}
-			event.ToModel = cfg.ModelStrong
-			event.Reason = triggerReason
-			event.Cooldown = cfg.ModelEscalation.CooldownIters
-
-			state.CurrentModel = cfg.ModelStrong
-			state.EscalationCount++
-			state.MinStrongIterationsRemaining = cfg.ModelEscalation.CooldownIters
-			state.LastEscalationReason = triggerReason
+		ladder := modelLadder(cfg)
+		if currentModelTier(state, cfg, ladder) < len(ladder)-1 {
+			event = escalateModel(&state, cfg, triggerReason)
 		}
 	} else if suppressed != "" {
-		event.Type = "suppressed"
-		event.Reason = fmt.Sprintf("trigger=%s, blocker=%s", triggerReason, suppressed)
-		event.Cooldown = state.MinStrongIterationsRemaining
-		event.FromModel = state.CurrentModel
-		if event.FromModel == "" {
-			event.FromModel = cfg.ModelDefault
-		}
-		// Suppressed means we wanted to go to Strong but couldn't
-		event.ToModel = cfg.ModelStrong
+		event = suppressedEscalation(state, cfg, fmt.Sprintf("trigger=%s, blocker=%s", triggerReason, suppressed))
 	} else if shouldDeescalateModel(state, cfg) {
-		event.Type = "de_escalated"
-		event.FromModel = state.CurrentModel
-		event.ToModel = cfg.ModelDefault
-		event.Reason = "min_strong_iterations_expired"
-
-		// De-escalate to default model
-		state.CurrentModel = cfg.ModelDefault
-		state.LastEscalationReason = ""
+		event = deescalateModel(&state, cfg)
 	}
 
 	return state, event
 }
+
+// logEntry renders the event as a model_escalation log entry. Tier indexes
+// are always recorded, including rung 0.
+func (e escalationEvent) logEntry(escalationCount int) logEntry {
+	from, to := e.FromTier, e.ToTier
+	entry := logEntry{
+		Type:             "model_escalation",
+		FromModel:        e.FromModel,
+		ToModel:          e.ToModel,
+		FromTier:         &from,
+		ToTier:           &to,
+		EscalationReason: e.Reason,
+		Escalated:        e.Type == "escalated",
+		Cooldown:         e.Cooldown,
+	}
+	if e.Type == "escalated" {
+		entry.EscalationCount = escalationCount
+	}
+	return entry
+}
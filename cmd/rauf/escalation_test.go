@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 )
@@ -394,6 +395,22 @@ func TestUpdateEscalationState(t *testing.T) {
 	})
 }
 
+func TestEscalateModelCooldownFloor(t *testing.T) {
+	legacy := runtimeConfig{ModelDefault: "sonnet", ModelStrong: "opus"}
+	state := raufState{}
+	escalateModel(&state, legacy, "consecutive_verify_fails")
+	if state.CurrentModel != "opus" || state.MinStrongIterationsRemaining != 0 {
+		t.Fatalf("expected legacy cooldown_iters: 0 to be kept, got %+v", state)
+	}
+
+	tiered := runtimeConfig{ModelDefault: "haiku", Models: []modelTier{{Name: "haiku"}, {Name: "sonnet"}, {Name: "opus"}}}
+	state = raufState{}
+	escalateModel(&state, tiered, "consecutive_verify_fails")
+	if state.CurrentModel != "sonnet" || state.MinStrongIterationsRemaining != 1 {
+		t.Fatalf("expected a multi-tier ladder to stay at least one iteration, got %+v", state)
+	}
+}
+
 func TestUpdateEscalationState_Suppression(t *testing.T) {
 	cfg := runtimeConfig{
 		ModelEscalation: escalationConfig{
@@ -450,3 +467,141 @@ func TestUpdateEscalationState_Deescalation(t *testing.T) {
 		t.Errorf("event.Type = %q, want 'none'", event.Type)
 	}
 }
+
+func TestModelLadderClimbsOneRungAtATime(t *testing.T) {
+	cfg := runtimeConfig{
+		ModelDefault: "haiku",
+		Models: []modelTier{
+			{Name: "haiku"},
+			{Name: "sonnet", ConsecutiveVerifyFails: 3, CooldownIters: 1},
+			{Name: "opus", CooldownIters: 3},
+		},
+		ModelEscalation: escalationConfig{
+			Enabled:                true,
+			ConsecutiveVerifyFails: 2,
+			CooldownIters:          2,
+			MaxEscalations:         5,
+		},
+	}
+
+	state := raufState{ConsecutiveVerifyFails: 2}
+	state, event := updateModelEscalationState(state, cfg)
+	if event.Type != "escalated" || event.FromTier != 0 || event.ToTier != 1 || state.CurrentModel != "sonnet" || state.MinStrongIterationsRemaining != 1 {
+		t.Fatalf("expected climb to sonnet, got %+v state=%+v", event, state)
+	}
+
+	// Sonnet's own threshold (3) applies while on sonnet.
+	state, event = updateModelEscalationState(state, cfg)
+	if event.Type != "none" || state.CurrentModel != "sonnet" {
+		t.Fatalf("expected to stay on sonnet below its threshold, got %+v", event)
+	}
+	state.ConsecutiveVerifyFails = 3
+	state, event = updateModelEscalationState(state, cfg)
+	if event.Type != "escalated" || event.ToTier != 2 || state.CurrentModel != "opus" || state.MinStrongIterationsRemaining != 3 {
+		t.Fatalf("expected climb to opus, got %+v state=%+v", event, state)
+	}
+
+	state.ConsecutiveVerifyFails = 5
+	state.MinStrongIterationsRemaining = 1
+	state, event = updateModelEscalationState(state, cfg)
+	if event.Type != "suppressed" || !strings.Contains(event.Reason, "top_tier_reached") || event.FromTier != 2 {
+		t.Fatalf("expected top tier suppression, got %+v", event)
+	}
+
+	// Success drops back one rung, with the lower rung's cooldown.
+	state.ConsecutiveVerifyFails = 0
+	state, event = updateModelEscalationState(state, cfg)
+	if event.Type != "de_escalated" || event.FromTier != 2 || event.ToTier != 1 || state.CurrentModel != "sonnet" || state.MinStrongIterationsRemaining != 1 {
+		t.Fatalf("expected drop to sonnet, got %+v state=%+v", event, state)
+	}
+	state, event = updateModelEscalationState(state, cfg)
+	if event.Type != "de_escalated" || event.ToTier != 0 || event.ToModel != "haiku" || state.CurrentModel != "" {
+		t.Fatalf("expected drop to haiku, got %+v state=%+v", event, state)
+	}
+	if state.EscalationCount != 2 {
+		t.Fatalf("EscalationCount = %d, want 2", state.EscalationCount)
+	}
+}
+
+func TestModelLadderLegacyPair(t *testing.T) {
+	cfg := runtimeConfig{ModelDefault: "sonnet", ModelStrong: "opus"}
+	ladder := modelLadder(cfg)
+	if len(ladder) != 2 || ladder[0].Name != "sonnet" || ladder[1].Name != "opus" {
+		t.Fatalf("unexpected legacy ladder: %+v", ladder)
+	}
+	if tier := currentModelTier(raufState{CurrentModel: "opus"}, cfg, ladder); tier != 1 {
+		t.Fatalf("tier = %d, want 1", tier)
+	}
+}
+
+func TestModelLadderLegacyPairKeepsBaselineSemantics(t *testing.T) {
+	cfg := runtimeConfig{
+		ModelDefault: "sonnet",
+		ModelStrong:  "opus",
+		ModelEscalation: escalationConfig{
+			Enabled:                true,
+			ConsecutiveVerifyFails: 2,
+			CooldownIters:          1,
+			MaxEscalations:         3,
+		},
+	}
+
+	// A trigger on the strong model after the cooldown is neither a top tier
+	// suppression nor a de-escalation: the run stays on the strong model.
+	state := raufState{CurrentModel: "opus", EscalationCount: 1, ConsecutiveVerifyFails: 4}
+	if ok, reason, suppressed := shouldEscalateModel(state, cfg); !ok || reason != "consecutive_verify_fails" || suppressed != "" {
+		t.Fatalf("expected the legacy trigger to be reported, got %t %q %q", ok, reason, suppressed)
+	}
+	state, event := updateModelEscalationState(state, cfg)
+	if event.Type != "none" || state.CurrentModel != "opus" || state.EscalationCount != 1 || state.MinStrongIterationsRemaining != 0 {
+		t.Fatalf("expected to stay on opus without an event, got %+v state=%+v", event, state)
+	}
+
+	// Without a trigger, the expired cooldown drops straight back.
+	state.ConsecutiveVerifyFails = 0
+	state, event = updateModelEscalationState(state, cfg)
+	if event.Type != "de_escalated" || event.ToModel != "sonnet" || state.CurrentModel != "" {
+		t.Fatalf("expected a drop to sonnet, got %+v state=%+v", event, state)
+	}
+}
+
+func TestModelLadderStaysWhenLowerRungWouldTrigger(t *testing.T) {
+	cfg := runtimeConfig{
+		ModelDefault: "haiku",
+		Models: []modelTier{
+			{Name: "haiku", ConsecutiveVerifyFails: 1},
+			{Name: "sonnet", ConsecutiveVerifyFails: 3},
+			{Name: "opus"},
+		},
+		ModelEscalation: escalationConfig{Enabled: true, MaxEscalations: 5},
+	}
+	// Above haiku's threshold but below sonnet's: dropping back would only
+	// climb again.
+	state := raufState{CurrentModel: "sonnet", EscalationCount: 1, ConsecutiveVerifyFails: 2}
+	state, event := updateModelEscalationState(state, cfg)
+	if event.Type != "none" || state.CurrentModel != "sonnet" {
+		t.Fatalf("expected to stay on sonnet, got %+v state=%+v", event, state)
+	}
+}
+
+func TestEscalationLogEntryRecordsTiers(t *testing.T) {
+	entry := escalationEvent{Type: "de_escalated", FromTier: 1, ToTier: 0}.logEntry(3)
+	if entry.FromTier == nil || *entry.FromTier != 1 || entry.ToTier == nil || *entry.ToTier != 0 || entry.EscalationCount != 0 {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil || !strings.Contains(string(data), `"to_tier":0`) {
+		t.Fatalf("expected tier 0 in JSON, got %s err=%v", data, err)
+	}
+}
+
+func TestParseConfigModelLadder(t *testing.T) {
+	cfg := runtimeConfig{}
+	data := "models:\n  - haiku\n  - name: sonnet\n    consecutive_verify_fails: 3\n  - name: opus # top rung\n    cooldown_iters: 4\n"
+	if err := parseConfigBytes([]byte(data), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(cfg.Models) != 3 || cfg.Models[0].Name != "haiku" || cfg.Models[1].ConsecutiveVerifyFails != 3 || cfg.Models[2].Name != "opus" || cfg.Models[2].CooldownIters != 4 {
+		t.Fatalf("unexpected ladder: %+v", cfg.Models)
+	}
+}
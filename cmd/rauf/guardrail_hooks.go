@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultGuardrailHookTimeout = 60 * time.Second
+
+// guardrailHookInput is the JSON document written to each hook's stdin.
+type guardrailHookInput struct {
+	Mode         string   `json:"mode"`
+	Iteration    int      `json:"iteration"`
+	Task         string   `json:"task"`
+	VerifyStatus string   `json:"verify_status"`
+	HeadBefore   string   `json:"head_before"`
+	HeadAfter    string   `json:"head_after"`
+	ChangedFiles []string `json:"changed_files"`
+	PlanDiff     string   `json:"plan_diff"`
+}
+
+// guardrailVerdict is the JSON document a hook must print to stdout.
+// Severity "warn" reports a failure without blocking; anything else blocks.
+type guardrailVerdict struct {
+	OK       bool   `json:"ok"`
+	Reason   string `json:"reason"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+}
+
+type guardrailHookResult struct {
+	Hook    string
+	Verdict guardrailVerdict
+	Err     error
+}
+
+// blocking reports whether the result should fail the guardrail check.
+func (r guardrailHookResult) blocking() bool {
+	if r.Err != nil {
+		return true
+	}
+	return !r.Verdict.OK && !strings.EqualFold(r.Verdict.Severity, "warn")
+}
+
+// guardrailReason returns the reason code fed into backpressure and recovery.
+func (r guardrailHookResult) guardrailReason() string {
+	if r.Err != nil {
+		return "hook_error:" + hookName(r.Hook)
+	}
+	reason := strings.TrimSpace(r.Verdict.Reason)
+	if reason == "" {
+		reason = hookName(r.Hook)
+	}
+	return "hook:" + reason
+}
+
+func (r guardrailHookResult) message() string {
+	if r.Err != nil {
+		return r.Err.Error()
+	}
+	return strings.TrimSpace(r.Verdict.Message)
+}
+
+func hookName(command string) string {
+	fields, err := splitArgs(command)
+	if err != nil || len(fields) == 0 {
+		return strings.TrimSpace(command)
+	}
+	return fields[0]
+}
+
+var runGuardrailHook = func(ctx context.Context, command string, input []byte) ([]byte, error) {
+	fields, err := splitArgs(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty guardrail hook command")
+	}
+	cmd := execCommand(ctx, fields[0], fields[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if err := cmd.Run(); err != nil {
+		return stdout.Bytes(), err
+	}
+	return stdout.Bytes(), nil
+}
+
+// runGuardrailHooks invokes every configured hook in order and returns all results.
+// Hooks that cannot be run or return malformed verdicts fail closed.
+func runGuardrailHooks(ctx context.Context, hooks []string, timeout time.Duration, input guardrailHookInput) []guardrailHookResult {
+	if timeout <= 0 {
+		timeout = defaultGuardrailHookTimeout
+	}
+	if input.ChangedFiles == nil {
+		input.ChangedFiles = []string{}
+	}
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return []guardrailHookResult{{Hook: "rauf", Err: err}}
+	}
+	results := make([]guardrailHookResult, 0, len(hooks))
+	for _, hook := range hooks {
+		hook = strings.TrimSpace(hook)
+		if hook == "" {
+			continue
+		}
+		hookCtx, cancel := context.WithTimeout(ctx, timeout)
+		output, runErr := runGuardrailHook(hookCtx, hook, payload)
+		timedOut := hookCtx.Err() == context.DeadlineExceeded
+		cancel()
+		result := guardrailHookResult{Hook: hook}
+		switch {
+		case timedOut:
+			result.Err = fmt.Errorf("guardrail hook %s timed out after %s", hookName(hook), timeout)
+		case runErr != nil:
+			result.Err = fmt.Errorf("guardrail hook %s failed: %w", hookName(hook), runErr)
+		default:
+			verdict, parseErr := parseGuardrailVerdict(output)
+			if parseErr != nil {
+				result.Err = fmt.Errorf("guardrail hook %s returned an invalid verdict: %w", hookName(hook), parseErr)
+			} else {
+				result.Verdict = verdict
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// parseGuardrailVerdict accepts either a single JSON document or output whose
+// last JSON object line is the verdict, so hooks may print diagnostics first.
+func parseGuardrailVerdict(output []byte) (guardrailVerdict, error) {
+	var verdict guardrailVerdict
+	if err := json.Unmarshal(bytes.TrimSpace(output), &verdict); err == nil {
+		return verdict, nil
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		if err := json.Unmarshal([]byte(line), &verdict); err == nil {
+			return verdict, nil
+		}
+	}
+	return guardrailVerdict{}, fmt.Errorf("no JSON verdict in output")
+}
+
+// firstBlockingHook returns the first result that should fail the guardrail check.
+func firstBlockingHook(results []guardrailHookResult) (guardrailHookResult, bool) {
+	for _, result := range results {
+		if result.blocking() {
+			return result, true
+		}
+	}
+	return guardrailHookResult{}, false
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGuardrailHookHelperProcess(t *testing.T) {
+	if os.Getenv("RAUF_HOOK_HELPER") != "1" {
+		return
+	}
+	data, _ := io.ReadAll(os.Stdin)
+	var input guardrailHookInput
+	if err := json.Unmarshal(data, &input); err != nil {
+		os.Exit(3)
+	}
+	if input.Task == "block me" {
+		_, _ = os.Stdout.WriteString("checking...\n")
+		_, _ = os.Stdout.WriteString(`{"ok": false, "reason": "license_header", "message": "missing header in a.go", "severity": "error"}` + "\n")
+	} else {
+		_, _ = os.Stdout.WriteString(`{"ok": true}` + "\n")
+	}
+	os.Exit(0)
+}
+
+func TestRunGuardrailHooksProcess(t *testing.T) {
+	t.Setenv("RAUF_HOOK_HELPER", "1")
+	hook := os.Args[0] + " -test.run=TestGuardrailHookHelperProcess"
+
+	results := runGuardrailHooks(context.Background(), []string{hook}, 10*time.Second, guardrailHookInput{Task: "block me"})
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	blocked, ok := firstBlockingHook(results)
+	if !ok {
+		t.Fatalf("expected blocking verdict, got %+v", results[0])
+	}
+	if blocked.guardrailReason() != "hook:license_header" {
+		t.Fatalf("unexpected reason: %q", blocked.guardrailReason())
+	}
+	if blocked.message() != "missing header in a.go" {
+		t.Fatalf("unexpected message: %q", blocked.message())
+	}
+
+	results = runGuardrailHooks(context.Background(), []string{hook}, 10*time.Second, guardrailHookInput{Task: "fine"})
+	if _, ok := firstBlockingHook(results); ok {
+		t.Fatalf("expected pass, got %+v", results)
+	}
+}
+
+func TestRunGuardrailHooksVerdicts(t *testing.T) {
+	orig := runGuardrailHook
+	defer func() { runGuardrailHook = orig }()
+
+	var gotInput guardrailHookInput
+	runGuardrailHook = func(ctx context.Context, command string, input []byte) ([]byte, error) {
+		_ = json.Unmarshal(input, &gotInput)
+		switch hookName(command) {
+		case "warn-hook":
+			return []byte(`{"ok": false, "reason": "style", "message": "prefer tabs", "severity": "warn"}`), nil
+		case "bad-json":
+			return []byte("not json"), nil
+		case "crash":
+			return nil, errors.New("exit status 2")
+		default:
+			return []byte(`{"ok": true}`), nil
+		}
+	}
+
+	input := guardrailHookInput{Mode: "build", Iteration: 3, HeadBefore: "a", HeadAfter: "b"}
+
+	results := runGuardrailHooks(context.Background(), []string{"ok-hook", "warn-hook"}, 0, input)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if _, ok := firstBlockingHook(results); ok {
+		t.Fatalf("warn severity must not block: %+v", results)
+	}
+	if gotInput.Iteration != 3 || gotInput.ChangedFiles == nil {
+		t.Fatalf("unexpected hook input: %+v", gotInput)
+	}
+
+	results = runGuardrailHooks(context.Background(), []string{"bad-json"}, 0, input)
+	blocked, ok := firstBlockingHook(results)
+	if !ok || blocked.guardrailReason() != "hook_error:bad-json" {
+		t.Fatalf("expected malformed verdict to fail closed, got %+v", results)
+	}
+
+	results = runGuardrailHooks(context.Background(), []string{"crash --flag"}, 0, input)
+	blocked, ok = firstBlockingHook(results)
+	if !ok || blocked.guardrailReason() != "hook_error:crash" {
+		t.Fatalf("expected hook failure to fail closed, got %+v", results)
+	}
+}
+
+func TestParseGuardrailVerdictMultiline(t *testing.T) {
+	verdict, err := parseGuardrailVerdict([]byte("{\n  \"ok\": false,\n  \"reason\": \"r\"\n}\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verdict.OK || verdict.Reason != "r" {
+		t.Fatalf("unexpected verdict: %+v", verdict)
+	}
+}
+
+func TestBackpressureIncludesGuardrailMessage(t *testing.T) {
+	state := raufState{
+		PriorGuardrailStatus:  "fail",
+		PriorGuardrailReason:  "hook:license_header",
+		PriorGuardrailMessage: "missing header in a.go",
+	}
+	pack := buildBackpressurePack(state, true)
+	if !strings.Contains(pack, "missing header in a.go") {
+		t.Fatalf("expected hook message in backpressure pack, got:\n%s", pack)
+	}
+	if !strings.Contains(pack, "guardrail hook rejected") {
+		t.Fatalf("expected hook action in backpressure pack, got:\n%s", pack)
+	}
+}
+
+func TestParseConfigGuardrailHooks(t *testing.T) {
+	cfg := runtimeConfig{}
+	data := "guardrail_hooks:\n  - ./hooks/license.sh\n  - \"python3 check.py --strict\"\nguardrail_hook_timeout: 5s\n"
+	if err := parseConfigBytes([]byte(data), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(cfg.GuardrailHooks) != 2 || cfg.GuardrailHooks[1] != "python3 check.py --strict" {
+		t.Fatalf("unexpected hooks: %v", cfg.GuardrailHooks)
+	}
+	if cfg.GuardrailHookTimeout != 5*time.Second {
+		t.Fatalf("unexpected timeout: %s", cfg.GuardrailHookTimeout)
+	}
+}
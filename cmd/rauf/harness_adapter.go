@@ -0,0 +1,578 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// harnessOutput is what an adapter extracts from a harness run.
+// Final is the text sentinel detection runs on: the assistant's messages with
+// tool output removed, or the raw terminal output for the generic adapter.
+type harnessOutput struct {
+	Final        string
+	ToolCalls    int
+	InputTokens  int
+	OutputTokens int
+	Errors       []string
+	Structured   bool
+	// SessionID identifies the harness conversation, when the CLI reports one.
+	SessionID string
+}
+
+// harnessAdapter knows a harness CLI's flags and output format.
+type harnessAdapter interface {
+	name() string
+	// prepareArgs adds the flags needed for structured output.
+	prepareArgs(args []string) []string
+	// display wraps the terminal writer so structured output is rendered readably.
+	display(w io.Writer) io.Writer
+	// newParser returns a parser that extracts the final assistant message,
+	// tool calls, usage and errors from the harness's stdout lines.
+	newParser() harnessParser
+}
+
+// harnessParser accumulates what an adapter extracts, one stdout line at a
+// time, so nothing is lost when the raw output tail is truncated.
+type harnessParser interface {
+	// line receives one trimmed line that looks like a JSON object.
+	line(line []byte)
+	// finish returns the result. raw is the captured output, used as the
+	// final message when no structured events were seen.
+	finish(raw string) harnessOutput
+}
+
+// sessionAdapter is implemented by adapters whose CLI can resume a previous
+// conversation by ID.
+type sessionAdapter interface {
+	resumeArgs(args []string, sessionID string) []string
+}
+
+var harnessAdapters = map[string]func() harnessAdapter{
+	"generic":  func() harnessAdapter { return genericAdapter{} },
+	"claude":   func() harnessAdapter { return claudeAdapter{} },
+	"codex":    func() harnessAdapter { return codexAdapter{} },
+	"copilot":  func() harnessAdapter { return copilotAdapter{} },
+	"opencode": func() harnessAdapter { return opencodeAdapter{} },
+}
+
+// resolveHarnessAdapter picks an adapter by name. "auto" selects by the harness
+// executable name; unknown names and an empty setting use the generic adapter.
+func resolveHarnessAdapter(name, harness string) harnessAdapter {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "auto" {
+		name = strings.TrimSuffix(strings.ToLower(filepath.Base(harness)), ".exe")
+	}
+	if ctor, ok := harnessAdapters[name]; ok {
+		return ctor()
+	}
+	return genericAdapter{}
+}
+
+// parseHarnessOutput runs the adapter's parser over captured output.
+func parseHarnessOutput(adapter harnessAdapter, raw string) harnessOutput {
+	p := adapter.newParser()
+	forEachJSONLine(raw, p.line)
+	return p.finish(raw)
+}
+
+// newHarnessResult builds the result of a harness run. It uses what stream
+// parsed from stdout while the harness ran, and re-parses raw only when the
+// stream saw nothing (e.g. the built-in harnesses). Output holds the extracted
+// final message; RawOutput keeps the output tail for retry matching.
+func newHarnessResult(adapter harnessAdapter, stream *harnessStream, raw string, retries int, retryReason string) harnessResult {
+	var parsed harnessOutput
+	if stream != nil && stream.lines > 0 {
+		parsed = stream.parser.finish(raw)
+	} else {
+		parsed = parseHarnessOutput(adapter, raw)
+	}
+	return harnessResult{
+		Output:      parsed.Final,
+		RawOutput:   raw,
+		RetryCount:  retries,
+		RetryReason: retryReason,
+		Adapter:     adapter.name(),
+		ToolCalls:   parsed.ToolCalls,
+		Usage:       tokenUsage{InputTokens: parsed.InputTokens, OutputTokens: parsed.OutputTokens},
+		Errors:      parsed.Errors,
+		SessionID:   parsed.SessionID,
+	}
+}
+
+func (r runtimeExec) adapter() harnessAdapter {
+	if r.Adapter == nil {
+		return genericAdapter{}
+	}
+	return r.Adapter
+}
+
+// genericAdapter treats the harness as an opaque process.
+type genericAdapter struct{}
+
+func (genericAdapter) name() string                       { return "generic" }
+func (genericAdapter) prepareArgs(args []string) []string { return args }
+func (genericAdapter) display(w io.Writer) io.Writer      { return w }
+func (genericAdapter) newParser() harnessParser           { return rawParser{} }
+
+// rawParser ignores structure and returns the raw output as the final message.
+type rawParser struct {
+	stripANSI bool
+}
+
+func (rawParser) line([]byte) {}
+
+func (p rawParser) finish(raw string) harnessOutput {
+	if p.stripANSI {
+		raw = stripANSI(raw)
+	}
+	return harnessOutput{Final: raw}
+}
+
+// copilotAdapter: the Copilot CLI has no machine-readable output mode, so the
+// adapter only strips terminal escapes from the final message.
+type copilotAdapter struct{}
+
+func (copilotAdapter) name() string                       { return "copilot" }
+func (copilotAdapter) prepareArgs(args []string) []string { return args }
+func (copilotAdapter) display(w io.Writer) io.Writer      { return w }
+func (copilotAdapter) newParser() harnessParser {
+	return rawParser{stripANSI: true}
+}
+
+// claudeAdapter runs Claude Code with --output-format stream-json.
+type claudeAdapter struct{}
+
+func (claudeAdapter) name() string { return "claude" }
+
+func (claudeAdapter) prepareArgs(args []string) []string {
+	if hasArg(args, "--output-format") {
+		return args
+	}
+	if !hasArg(args, "-p") && !hasArg(args, "--print") {
+		args = append(args, "-p")
+	}
+	args = append(args, "--output-format", "stream-json")
+	if !hasArg(args, "--verbose") {
+		args = append(args, "--verbose")
+	}
+	return args
+}
+
+type claudeEvent struct {
+	Type      string `json:"type"`
+	Subtype   string `json:"subtype"`
+	IsError   bool   `json:"is_error"`
+	Result    string `json:"result"`
+	SessionID string `json:"session_id"`
+	Message   struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+			Name string `json:"name"`
+		} `json:"content"`
+	} `json:"message"`
+	Usage struct {
+		InputTokens              int `json:"input_tokens"`
+		CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+		OutputTokens             int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+func (claudeAdapter) display(w io.Writer) io.Writer {
+	return newJSONLineWriter(w, func(line []byte) (string, bool) {
+		var ev claudeEvent
+		if err := json.Unmarshal(line, &ev); err != nil {
+			return "", false
+		}
+		if ev.Type != "assistant" {
+			return "", true
+		}
+		var b strings.Builder
+		for _, part := range ev.Message.Content {
+			switch part.Type {
+			case "text":
+				b.WriteString(part.Text)
+				b.WriteString("\n")
+			case "tool_use":
+				fmt.Fprintf(&b, "[tool: %s]\n", part.Name)
+			}
+		}
+		return b.String(), true
+	})
+}
+
+func (claudeAdapter) newParser() harnessParser { return &claudeParser{} }
+
+type claudeParser struct {
+	out    harnessOutput
+	texts  []string
+	result string
+}
+
+func (p *claudeParser) line(line []byte) {
+	var ev claudeEvent
+	if err := json.Unmarshal(line, &ev); err != nil {
+		return
+	}
+	p.out.Structured = true
+	if ev.SessionID != "" {
+		p.out.SessionID = ev.SessionID
+	}
+	switch ev.Type {
+	case "assistant":
+		for _, part := range ev.Message.Content {
+			switch part.Type {
+			case "text":
+				p.texts = append(p.texts, part.Text)
+			case "tool_use":
+				p.out.ToolCalls++
+			}
+		}
+	case "result":
+		p.result = ev.Result
+		p.out.InputTokens = ev.Usage.InputTokens + ev.Usage.CacheCreationInputTokens + ev.Usage.CacheReadInputTokens
+		p.out.OutputTokens = ev.Usage.OutputTokens
+		if ev.IsError || strings.HasPrefix(ev.Subtype, "error") {
+			p.out.Errors = append(p.out.Errors, firstNonEmpty(ev.Result, ev.Subtype))
+		}
+	}
+}
+
+func (p *claudeParser) finish(raw string) harnessOutput {
+	if !p.out.Structured {
+		return harnessOutput{Final: raw}
+	}
+	out := p.out
+	out.Final = strings.Join(p.texts, "\n")
+	if out.Final == "" {
+		out.Final = p.result
+	}
+	return out
+}
+
+// codexAdapter runs `codex exec --json`, which emits one JSON event per line.
+type codexAdapter struct{}
+
+func (codexAdapter) name() string { return "codex" }
+
+func (codexAdapter) prepareArgs(args []string) []string {
+	if hasArg(args, "--json") {
+		return args
+	}
+	return append(args, "--json")
+}
+
+type codexEvent struct {
+	Type     string `json:"type"`
+	Message  string `json:"message"`
+	ThreadID string `json:"thread_id"`
+	Item     struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"item"`
+	Usage struct {
+		InputTokens       int `json:"input_tokens"`
+		CachedInputTokens int `json:"cached_input_tokens"`
+		OutputTokens      int `json:"output_tokens"`
+	} `json:"usage"`
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (codexAdapter) display(w io.Writer) io.Writer {
+	return newJSONLineWriter(w, func(line []byte) (string, bool) {
+		var ev codexEvent
+		if err := json.Unmarshal(line, &ev); err != nil {
+			return "", false
+		}
+		if ev.Type != "item.completed" {
+			return "", true
+		}
+		switch ev.Item.Type {
+		case "agent_message", "reasoning":
+			return ev.Item.Text + "\n", true
+		case "":
+			return "", true
+		default:
+			return fmt.Sprintf("[tool: %s]\n", ev.Item.Type), true
+		}
+	})
+}
+
+func (codexAdapter) newParser() harnessParser { return &codexParser{} }
+
+type codexParser struct {
+	out   harnessOutput
+	texts []string
+}
+
+func (p *codexParser) line(line []byte) {
+	var ev codexEvent
+	if err := json.Unmarshal(line, &ev); err != nil || ev.Type == "" {
+		return
+	}
+	p.out.Structured = true
+	switch ev.Type {
+	case "thread.started":
+		p.out.SessionID = ev.ThreadID
+	case "item.completed":
+		switch ev.Item.Type {
+		case "agent_message":
+			p.texts = append(p.texts, ev.Item.Text)
+		case "command_execution", "mcp_tool_call", "file_change", "web_search":
+			p.out.ToolCalls++
+		}
+	case "turn.completed":
+		p.out.InputTokens += ev.Usage.InputTokens
+		p.out.OutputTokens += ev.Usage.OutputTokens
+	case "turn.failed":
+		p.out.Errors = append(p.out.Errors, ev.Error.Message)
+	case "error":
+		p.out.Errors = append(p.out.Errors, ev.Message)
+	}
+}
+
+func (p *codexParser) finish(raw string) harnessOutput {
+	if !p.out.Structured {
+		return harnessOutput{Final: raw}
+	}
+	out := p.out
+	out.Final = strings.Join(p.texts, "\n")
+	return out
+}
+
+// opencodeAdapter runs `opencode run --format json`.
+type opencodeAdapter struct{}
+
+func (opencodeAdapter) name() string { return "opencode" }
+
+func (opencodeAdapter) prepareArgs(args []string) []string {
+	if hasArg(args, "--format") {
+		return args
+	}
+	return append(args, "--format", "json")
+}
+
+type opencodeEvent struct {
+	Type      string `json:"type"`
+	SessionID string `json:"sessionID"`
+	Part      struct {
+		Text      string `json:"text"`
+		Tool      string `json:"tool"`
+		SessionID string `json:"sessionID"`
+		Tokens    struct {
+			Input  int `json:"input"`
+			Output int `json:"output"`
+		} `json:"tokens"`
+	} `json:"part"`
+	Error json.RawMessage `json:"error"`
+}
+
+func (opencodeAdapter) display(w io.Writer) io.Writer {
+	return newJSONLineWriter(w, func(line []byte) (string, bool) {
+		var ev opencodeEvent
+		if err := json.Unmarshal(line, &ev); err != nil {
+			return "", false
+		}
+		switch ev.Type {
+		case "text":
+			return ev.Part.Text + "\n", true
+		case "tool_use":
+			return fmt.Sprintf("[tool: %s]\n", ev.Part.Tool), true
+		}
+		return "", true
+	})
+}
+
+func (opencodeAdapter) newParser() harnessParser { return &opencodeParser{} }
+
+type opencodeParser struct {
+	out   harnessOutput
+	texts []string
+}
+
+func (p *opencodeParser) line(line []byte) {
+	var ev opencodeEvent
+	if err := json.Unmarshal(line, &ev); err != nil || ev.Type == "" {
+		return
+	}
+	p.out.Structured = true
+	if id := firstNonEmpty(ev.SessionID, ev.Part.SessionID); id != "" {
+		p.out.SessionID = id
+	}
+	switch ev.Type {
+	case "text":
+		p.texts = append(p.texts, ev.Part.Text)
+	case "tool_use":
+		p.out.ToolCalls++
+	case "step_finish":
+		p.out.InputTokens += ev.Part.Tokens.Input
+		p.out.OutputTokens += ev.Part.Tokens.Output
+	case "error":
+		p.out.Errors = append(p.out.Errors, strings.Trim(string(ev.Error), `"`))
+	}
+}
+
+func (p *opencodeParser) finish(raw string) harnessOutput {
+	if !p.out.Structured {
+		return harnessOutput{Final: raw}
+	}
+	out := p.out
+	out.Final = strings.Join(p.texts, "\n")
+	return out
+}
+
+// resumeArgs continues a previous conversation with --resume.
+func (claudeAdapter) resumeArgs(args []string, sessionID string) []string {
+	if hasArg(args, "--resume") || hasArg(args, "--continue") {
+		return args
+	}
+	return append(args, "--resume", sessionID)
+}
+
+// resumeArgs switches `codex exec` to its `resume <id>` subcommand.
+func (codexAdapter) resumeArgs(args []string, sessionID string) []string {
+	if hasArg(args, "resume") {
+		return args
+	}
+	return append(args, "resume", sessionID)
+}
+
+// resumeArgs continues a previous conversation with --session.
+func (opencodeAdapter) resumeArgs(args []string, sessionID string) []string {
+	if hasArg(args, "--session") || hasArg(args, "--continue") {
+		return args
+	}
+	return append(args, "--session", sessionID)
+}
+
+func hasArg(args []string, flag string) bool {
+	for _, arg := range args {
+		if arg == flag || strings.HasPrefix(arg, flag+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// forEachJSONLine calls fn for every line that looks like a JSON object.
+func forEachJSONLine(raw string, fn func(line []byte)) {
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		fn(line)
+	}
+}
+
+// maxStreamLineBytes bounds one buffered stdout line. Longer lines (large
+// tool results) are skipped rather than held in memory.
+const maxStreamLineBytes = 16 * 1024 * 1024
+
+// harnessStream feeds the harness's stdout to an adapter parser line by line
+// as it is written, before any output is truncated.
+type harnessStream struct {
+	parser   harnessParser
+	buf      []byte
+	skipping bool
+	// lines counts the JSON-looking lines handed to the parser.
+	lines int
+}
+
+func newHarnessStream(adapter harnessAdapter) *harnessStream {
+	return &harnessStream{parser: adapter.newParser()}
+}
+
+func (s *harnessStream) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i == -1 {
+			s.appendPartial(p)
+			return n, nil
+		}
+		s.appendPartial(p[:i])
+		s.flush()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+func (s *harnessStream) appendPartial(p []byte) {
+	if s.skipping {
+		return
+	}
+	if len(s.buf)+len(p) > maxStreamLineBytes {
+		s.buf, s.skipping = s.buf[:0], true
+		return
+	}
+	s.buf = append(s.buf, p...)
+}
+
+// flush hands the buffered line to the parser. It is also called once the
+// harness exits, for a last line without a newline.
+func (s *harnessStream) flush() {
+	line := bytes.TrimSpace(s.buf)
+	if !s.skipping && len(line) > 0 && line[0] == '{' {
+		s.lines++
+		s.parser.line(line)
+	}
+	s.buf, s.skipping = s.buf[:0], false
+}
+
+// jsonLineWriter renders JSON event lines for the terminal. Lines the render
+// function does not recognise as JSON are passed through unchanged.
+type jsonLineWriter struct {
+	w      io.Writer
+	render func(line []byte) (string, bool)
+	buf    bytes.Buffer
+}
+
+func newJSONLineWriter(w io.Writer, render func(line []byte) (string, bool)) *jsonLineWriter {
+	return &jsonLineWriter{w: w, render: render}
+}
+
+func (jw *jsonLineWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	jw.buf.Write(p)
+	for {
+		b := jw.buf.Bytes()
+		i := bytes.IndexByte(b, '\n')
+		if i == -1 {
+			return n, nil
+		}
+		line := make([]byte, i+1)
+		copy(line, b[:i+1])
+		jw.buf.Next(i + 1)
+		if text, ok := jw.render(bytes.TrimSpace(line)); ok {
+			if text != "" {
+				if _, err := io.WriteString(jw.w, text); err != nil {
+					return n, err
+				}
+			}
+			continue
+		}
+		if _, err := jw.w.Write(line); err != nil {
+			return n, err
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveHarnessAdapter(t *testing.T) {
+	tests := []struct {
+		name    string
+		harness string
+		want    string
+	}{
+		{"", "claude", "generic"},
+		{"auto", "/usr/local/bin/claude", "claude"},
+		{"auto", "codex.exe", "codex"},
+		{"auto", "script", "generic"},
+		{"OpenCode", "script", "opencode"},
+		{"bogus", "claude", "generic"},
+	}
+	for _, tc := range tests {
+		if got := resolveHarnessAdapter(tc.name, tc.harness).name(); got != tc.want {
+			t.Errorf("resolveHarnessAdapter(%q, %q) = %s, want %s", tc.name, tc.harness, got, tc.want)
+		}
+	}
+}
+
+func TestAdapterPrepareArgs(t *testing.T) {
+	got := claudeAdapter{}.prepareArgs([]string{"--model", "opus"})
+	want := []string{"--model", "opus", "-p", "--output-format", "stream-json", "--verbose"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("claude args = %v, want %v", got, want)
+	}
+	explicit := []string{"-p", "--output-format=text"}
+	if got := (claudeAdapter{}).prepareArgs(explicit); !reflect.DeepEqual(got, explicit) {
+		t.Fatalf("expected explicit output format to be kept, got %v", got)
+	}
+	if got := (codexAdapter{}).prepareArgs([]string{"exec"}); !reflect.DeepEqual(got, []string{"exec", "--json"}) {
+		t.Fatalf("codex args = %v", got)
+	}
+	if got := (opencodeAdapter{}).prepareArgs([]string{"run"}); !reflect.DeepEqual(got, []string{"run", "--format", "json"}) {
+		t.Fatalf("opencode args = %v", got)
+	}
+}
+
+func TestClaudeAdapterParse(t *testing.T) {
+	raw := strings.Join([]string{
+		`{"type":"system","subtype":"init"}`,
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"Will change: a.go"},{"type":"tool_use","name":"Bash"}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","content":"RAUF_COMPLETE"}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Edit"}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"Done."}]}}`,
+		`{"type":"result","subtype":"success","is_error":false,"result":"Done.","usage":{"input_tokens":10,"cache_read_input_tokens":90,"output_tokens":25}}`,
+	}, "\n")
+	out := parseHarnessOutput(claudeAdapter{}, raw)
+	if out.Final != "Will change: a.go\nDone." {
+		t.Fatalf("unexpected final text: %q", out.Final)
+	}
+	if hasCompletionSentinel(out.Final) {
+		t.Fatalf("tool output must not trigger the completion sentinel")
+	}
+	if out.ToolCalls != 2 || out.InputTokens != 100 || out.OutputTokens != 25 || len(out.Errors) != 0 {
+		t.Fatalf("unexpected parse result: %+v", out)
+	}
+
+	failed := parseHarnessOutput(claudeAdapter{}, `{"type":"result","subtype":"error_max_turns","is_error":true,"result":""}`)
+	if len(failed.Errors) != 1 || failed.Errors[0] != "error_max_turns" {
+		t.Fatalf("expected error from result event, got %+v", failed)
+	}
+
+	plain := parseHarnessOutput(claudeAdapter{}, "not json\nRAUF_COMPLETE\n")
+	if plain.Structured || plain.Final != "not json\nRAUF_COMPLETE\n" {
+		t.Fatalf("expected raw fallback, got %+v", plain)
+	}
+}
+
+func TestCodexAdapterParse(t *testing.T) {
+	raw := strings.Join([]string{
+		`{"type":"thread.started","thread_id":"abc"}`,
+		`{"type":"item.completed","item":{"type":"command_execution","command":"go test"}}`,
+		`{"type":"item.completed","item":{"type":"agent_message","text":"RAUF_COMPLETE"}}`,
+		`{"type":"turn.completed","usage":{"input_tokens":40,"output_tokens":7}}`,
+	}, "\n")
+	out := parseHarnessOutput(codexAdapter{}, raw)
+	if !hasCompletionSentinel(out.Final) || out.ToolCalls != 1 || out.InputTokens != 40 || out.OutputTokens != 7 {
+		t.Fatalf("unexpected parse result: %+v", out)
+	}
+}
+
+func TestOpencodeAdapterParse(t *testing.T) {
+	raw := strings.Join([]string{
+		`{"type":"tool_use","part":{"tool":"bash"}}`,
+		`{"type":"text","part":{"text":"HYPOTHESIS: stale cache"}}`,
+		`{"type":"step_finish","part":{"tokens":{"input":5,"output":3}}}`,
+		`{"type":"error","error":"quota"}`,
+	}, "\n")
+	out := parseHarnessOutput(opencodeAdapter{}, raw)
+	if out.Final != "HYPOTHESIS: stale cache" || out.ToolCalls != 1 || out.InputTokens != 5 || len(out.Errors) != 1 {
+		t.Fatalf("unexpected parse result: %+v", out)
+	}
+}
+
+func TestJSONLineWriterRendersEvents(t *testing.T) {
+	var buf bytes.Buffer
+	w := claudeAdapter{}.display(&buf)
+	_, _ = w.Write([]byte(`{"type":"assistant","message":{"content":[{"type":"text","text":"hello"},{"type":"tool_use","name":"Bash"}]}}` + "\n" + `{"type":"res`))
+	_, _ = w.Write([]byte(`ult"}` + "\nplain line\n"))
+	if got := buf.String(); got != "hello\n[tool: Bash]\nplain line\n" {
+		t.Fatalf("unexpected rendered output: %q", got)
+	}
+}
+
+func TestRunHarnessUsesAdapter(t *testing.T) {
+	orig := execCommand
+	defer func() { execCommand = orig }()
+
+	var gotArgs []string
+	execCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		gotArgs = args
+		return exec.CommandContext(ctx, "echo", `{"type":"item.completed","item":{"type":"agent_message","text":"RAUF_COMPLETE"}}`)
+	}
+	runner := runtimeExec{Quiet: true, Adapter: codexAdapter{}}
+	res, err := runHarness(context.Background(), "prompt", "codex", "exec --full-auto", nil, retryConfig{}, runner)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(gotArgs, []string{"exec", "--full-auto", "--json"}) {
+		t.Fatalf("unexpected args: %v", gotArgs)
+	}
+	if res.Output != "RAUF_COMPLETE" || res.Adapter != "codex" || !strings.Contains(res.RawOutput, "item.completed") {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+func TestRunHarnessParsesStdoutBeyondOutputTail(t *testing.T) {
+	dir := t.TempDir()
+	var events strings.Builder
+	events.WriteString(`{"type":"system","subtype":"init","session_id":"s-1"}` + "\n")
+	events.WriteString(`{"type":"assistant","message":{"content":[{"type":"text","text":"Will change: a.go"},{"type":"tool_use","name":"Bash"}]}}` + "\n")
+	toolResult := `{"type":"user","message":{"content":[{"type":"tool_result","content":"` + strings.Repeat("x", 4096) + `"}]}}` + "\n"
+	for events.Len() < 2*1024*1024 {
+		events.WriteString(toolResult)
+	}
+	events.WriteString(`{"type":"assistant","message":{"content":[{"type":"text","text":"Done."}]}}` + "\n")
+	events.WriteString(`{"type":"result","subtype":"success","result":"Done.","usage":{"input_tokens":10,"output_tokens":5}}`)
+	path := filepath.Join(dir, "events.jsonl")
+	if err := os.WriteFile(path, []byte(events.String()), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	orig := execCommand
+	defer func() { execCommand = orig }()
+	execCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		// Stderr chatter interleaves with stdout in the raw output.
+		return exec.CommandContext(ctx, "sh", "-c", `for i in 1 2 3; do echo "warning: {partial" >&2; done; cat "$0"`, path)
+	}
+	res, err := runHarness(context.Background(), "prompt", "claude", "", nil, retryConfig{}, runtimeExec{Quiet: true, Adapter: claudeAdapter{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.RawOutput) > 1024*1024 {
+		t.Fatalf("expected the raw output tail to stay bounded, got %d bytes", len(res.RawOutput))
+	}
+	if res.SessionID != "s-1" || res.Output != "Will change: a.go\nDone." || res.ToolCalls != 1 || res.Usage.InputTokens != 10 {
+		t.Fatalf("expected the whole stream to be parsed, got session=%q output=%q tools=%d usage=%+v", res.SessionID, res.Output, res.ToolCalls, res.Usage)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// dockerWorkspace is where docker runtimes mount the workspace.
+const dockerWorkspace = "/workspace"
+
+// promptFileDir holds rendered prompts passed via {prompt_file}. It lives in
+// the workspace so docker runtimes see it through the workspace mount.
+const promptFileDir = ".rauf"
+
+// harnessPath maps a host path inside the workspace to the path the harness
+// sees: unchanged on the host, under /workspace in docker runtimes.
+func (r runtimeExec) harnessPath(path string) string {
+	workdir, err := r.resolveWorkDir()
+	if err != nil {
+		return path
+	}
+	abs := path
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(workdir, path)
+	}
+	if !r.isDocker() && !r.isDockerPersist() {
+		return abs
+	}
+	rel, err := filepath.Rel(workdir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return abs
+	}
+	if rel == "." {
+		return dockerWorkspace
+	}
+	return dockerWorkspace + "/" + filepath.ToSlash(rel)
+}
+
+// expandHarnessArgs substitutes {prompt}, {prompt_file}, {model}, {mode},
+// {iteration}, {attempt}, {task_id}, {log_path} and {workdir} in harness args. It reports
+// whether the prompt was delivered through the args (so stdin is not needed)
+// and returns a cleanup func that removes the prompt file.
+func expandHarnessArgs(args []string, prompt string, logFile *os.File, runner runtimeExec) ([]string, bool, func(), error) {
+	cleanup := func() {}
+	logPath := ""
+	if logFile != nil {
+		logPath = runner.harnessPath(logFile.Name())
+	}
+	promptFile := ""
+	for _, arg := range args {
+		if strings.Contains(arg, "{prompt_file}") {
+			path, err := writePromptFile(filepath.Join(runner.WorkDir, promptFileDir), prompt)
+			if err != nil {
+				return nil, false, cleanup, err
+			}
+			cleanup = func() { _ = os.Remove(path) }
+			promptFile = runner.harnessPath(path)
+			break
+		}
+	}
+	// A single pass keeps substitution deterministic and never re-expands
+	// placeholder-like text inside a substituted value such as the prompt.
+	replacer := strings.NewReplacer(
+		"{prompt_file}", promptFile,
+		"{prompt}", prompt,
+		"{model}", runner.Model,
+		"{mode}", runner.Mode,
+		"{iteration}", strconv.Itoa(runner.runIteration()),
+		"{attempt}", strconv.Itoa(runner.Attempt),
+		"{task_id}", runner.TaskID,
+		"{log_path}", logPath,
+		"{workdir}", runner.harnessPath("."),
+	)
+
+	promptInArgs := false
+	out := make([]string, len(args))
+	for i, arg := range args {
+		if strings.Contains(arg, "{prompt_file}") || strings.Contains(arg, "{prompt}") {
+			promptInArgs = true
+		}
+		out[i] = replacer.Replace(arg)
+	}
+	return out, promptInArgs, cleanup, nil
+}
+
+func writePromptFile(dir, prompt string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(dir, "prompt-*.md")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(prompt); err != nil {
+		f.Close()
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandHarnessArgs(t *testing.T) {
+	dir := t.TempDir()
+	chdirTemp(t, dir)
+	logFile, err := os.Create("run.jsonl")
+	if err != nil {
+		t.Fatalf("create log failed: %v", err)
+	}
+	defer logFile.Close()
+
+	runner := runtimeExec{WorkDir: dir, Mode: "build", Iteration: 3, Model: "opus", TaskID: "T7"}
+	args := []string{"--model", "{model}", "--out={workdir}/out-{mode}-{iteration}-{task_id}.json", "--log", "{log_path}", "--prompt-file", "{prompt_file}"}
+	got, promptInArgs, cleanup, err := expandHarnessArgs(args, "the {model} prompt", logFile, runner)
+	if err != nil {
+		t.Fatalf("expand failed: %v", err)
+	}
+	if !promptInArgs {
+		t.Fatalf("expected {prompt_file} to count as prompt delivery")
+	}
+	if got[1] != "opus" || got[2] != "--out="+dir+"/out-build-3-T7.json" || got[4] != filepath.Join(dir, "run.jsonl") {
+		t.Fatalf("unexpected args: %v", got)
+	}
+	data, err := os.ReadFile(got[6])
+	if err != nil || string(data) != "the {model} prompt" {
+		t.Fatalf("expected prompt file with raw prompt, got %q err=%v", data, err)
+	}
+	if !strings.HasPrefix(got[6], filepath.Join(dir, ".rauf", "prompt-")) {
+		t.Fatalf("expected prompt file under .rauf, got %s", got[6])
+	}
+	cleanup()
+	if _, err := os.Stat(got[6]); !os.IsNotExist(err) {
+		t.Fatalf("expected prompt file to be removed")
+	}
+
+	got, promptInArgs, _, err = expandHarnessArgs([]string{"-p", "{prompt}"}, "say {mode}", nil, runner)
+	if err != nil || !promptInArgs || got[1] != "say {mode}" {
+		t.Fatalf("unexpected {prompt} expansion: %v %t %v", got, promptInArgs, err)
+	}
+
+	// Values that look like placeholders are never expanded again.
+	tricky := runtimeExec{WorkDir: dir, Mode: "{task_id}", Model: "{mode}", TaskID: "{model}"}
+	for i := 0; i < 20; i++ {
+		got, _, _, err = expandHarnessArgs([]string{"{model}/{mode}/{task_id}"}, "", nil, tricky)
+		if err != nil || got[0] != "{mode}/{task_id}/{model}" {
+			t.Fatalf("expected single-pass substitution, got %v err=%v", got, err)
+		}
+	}
+}
+
+func TestHarnessPathDocker(t *testing.T) {
+	dir := t.TempDir()
+	runner := runtimeExec{Runtime: "docker", WorkDir: dir}
+	if got := runner.harnessPath(".rauf/prompt-1.md"); got != "/workspace/.rauf/prompt-1.md" {
+		t.Fatalf("unexpected docker path: %s", got)
+	}
+	if got := runner.harnessPath("."); got != "/workspace" {
+		t.Fatalf("unexpected docker workdir: %s", got)
+	}
+	if got := runner.harnessPath("/elsewhere/file"); got != "/elsewhere/file" {
+		t.Fatalf("paths outside the workspace must be kept, got %s", got)
+	}
+}
+
+func TestRunHarnessOncePromptFile(t *testing.T) {
+	dir := t.TempDir()
+	chdirTemp(t, dir)
+
+	orig := execCommand
+	defer func() { execCommand = orig }()
+	var gotArgs []string
+	execCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		gotArgs = args
+		return exec.CommandContext(ctx, "cat", args[len(args)-1])
+	}
+
+	out, err := runHarnessOnce(context.Background(), "PROMPT BODY", "harness", "run --file {prompt_file}", nil, runtimeExec{Quiet: true, WorkDir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "PROMPT BODY" {
+		t.Fatalf("expected harness to read the prompt file, got %q (args %v)", out, gotArgs)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, ".rauf"))
+	if len(entries) != 0 {
+		t.Fatalf("expected prompt file cleanup, found %d entries", len(entries))
+	}
+}
+
+func TestExpandHarnessArgsIterationAcrossStrategy(t *testing.T) {
+	dir := t.TempDir()
+	chdirTemp(t, dir)
+	files := map[string]string{
+		"PROMPT_build.md": "build",
+		"PLAN.md":         "# Plan\n- [ ] T1: write output\n  - Verify: true\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+	}
+
+	orig := runHarnessOnce
+	defer func() { runHarnessOnce = orig }()
+	var got []string
+	runHarnessOnce = func(ctx context.Context, prompt string, harness, harnessArgs string, logFile *os.File, runner runtimeExec) (string, error) {
+		args, _, cleanup, err := expandHarnessArgs([]string{"--out=out-{iteration}.json"}, prompt, logFile, runner)
+		defer cleanup()
+		if err != nil {
+			return "", err
+		}
+		got = append(got, args[0])
+		// Change the workspace so every iteration makes progress.
+		return "", os.WriteFile(strings.TrimPrefix(args[0], "--out="), []byte("{}"), 0o644)
+	}
+
+	fileCfg := runtimeConfig{NoProgressIters: 5, Strategy: []strategyStep{
+		{Mode: "build", Iterations: 2},
+		{Mode: "build", Iterations: 1},
+	}}
+	if err := runStrategy(context.Background(), modeConfig{}, fileCfg, runtimeExec{Quiet: true}, raufState{}, false, "", "PLAN.md", "harness", "", true, "logs", false, 0, 0, 0, false, nil, nil, nil, &RunReport{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(got, ",") != "--out=out-1.json,--out=out-2.json,--out=out-3.json" {
+		t.Fatalf("expected {iteration} to count across strategy steps, got %v", got)
+	}
+}
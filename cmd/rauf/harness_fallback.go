@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// harnessTarget is one harness in the fallback chain. Index 0 is the primary.
+type harnessTarget struct {
+	Harness     string `json:"harness"`
+	HarnessArgs string `json:"harness_args,omitempty"`
+	Model       string `json:"model,omitempty"`
+	Adapter     string `json:"adapter,omitempty"`
+}
+
+// fallbackPolicy lists the failure classes that switch to the next harness.
+type fallbackPolicy struct {
+	ExitCodes []int
+	Patterns  []string
+	// Timeouts is the number of consecutive attempt timeouts on one harness
+	// before switching; 0 means timeouts never trigger a fallback.
+	Timeouts int
+}
+
+// harnessFallbackEvent records a switch between harnesses.
+type harnessFallbackEvent struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+}
+
+func (t harnessTarget) label() string {
+	if t.Model != "" {
+		return t.Harness + " (" + t.Model + ")"
+	}
+	return t.Harness
+}
+
+// harnessChain returns the primary target followed by the configured fallbacks.
+func harnessChain(cfg runtimeConfig, harness, harnessArgs string) []harnessTarget {
+	chain := []harnessTarget{{Harness: harness, HarnessArgs: harnessArgs, Adapter: cfg.HarnessAdapter}}
+	for _, fb := range cfg.HarnessFallbacks {
+		if strings.TrimSpace(fb.Harness) == "" {
+			continue
+		}
+		chain = append(chain, fb)
+	}
+	return chain
+}
+
+// targetArgs applies the target's model to its harness args.
+func targetArgs(cfg runtimeConfig, target harnessTarget) string {
+	if target.Model == "" || strings.Contains(target.HarnessArgs, "{model}") {
+		return target.HarnessArgs
+	}
+	return applyModelChoice(target.HarnessArgs, cfg.ModelFlag, target.Model, true)
+}
+
+// classifyHarnessFailure returns the failure class that should trigger a
+// fallback, or "" when the failure is not covered by the policy.
+// A harness that cannot be started at all always qualifies.
+func classifyHarnessFailure(policy fallbackPolicy, output string, err error) string {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, os.ErrNotExist) {
+		return "not_found"
+	}
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		for _, want := range policy.ExitCodes {
+			if code == want {
+				return fmt.Sprintf("exit_code:%d", code)
+			}
+		}
+	}
+	lower := strings.ToLower(stripANSI(output))
+	for _, pattern := range policy.Patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern != "" && strings.Contains(lower, strings.ToLower(pattern)) {
+			return "pattern:" + pattern
+		}
+	}
+	return ""
+}
+
+// tickFallbackCooldown tracks how long to stay on a fallback before retrying
+// the primary. Called at the start of each iteration; returns an event when the
+// cooldown expires and the chain resets to the primary.
+func tickFallbackCooldown(state *raufState, chain []harnessTarget) (harnessFallbackEvent, bool) {
+	if state.HarnessFallbackIndex <= 0 {
+		return harnessFallbackEvent{}, false
+	}
+	if state.HarnessFallbackIndex >= len(chain) {
+		state.HarnessFallbackIndex = 0
+		state.HarnessFallbackCooldown = 0
+		return harnessFallbackEvent{}, false
+	}
+	if state.HarnessFallbackCooldown > 0 {
+		state.HarnessFallbackCooldown--
+		return harnessFallbackEvent{}, false
+	}
+	event := harnessFallbackEvent{
+		From:   chain[state.HarnessFallbackIndex].label(),
+		To:     chain[0].label(),
+		Reason: "cooldown_expired",
+	}
+	state.HarnessFallbackIndex = 0
+	return event, true
+}
+
+// runHarnessChain runs the active harness and walks the fallback chain on
+// qualifying failures. Each attempt gets its own timeout.
+func runHarnessChain(ctx context.Context, attemptTimeout time.Duration, prompt string, chain []harnessTarget, cfg runtimeConfig, state *raufState, logFile *os.File, retry retryConfig, runner runtimeExec, mode string, iteration int) (harnessResult, harnessTarget, []harnessFallbackEvent, error) {
+	events := []harnessFallbackEvent{}
+	idx := state.HarnessFallbackIndex
+	if idx < 0 || idx >= len(chain) {
+		idx = 0
+	}
+	timeouts := 0
+	for {
+		target := chain[idx]
+		attemptRunner := runner
+		attemptRunner.Adapter = resolveHarnessAdapter(firstNonEmpty(target.Adapter, cfg.HarnessAdapter), target.Harness)
+		attemptRunner.Model = firstNonEmpty(target.Model, runner.Model)
+		if target.Harness != state.SessionHarness {
+			attemptRunner.SessionID = ""
+		}
+
+		attemptCtx := ctx
+		cancel := func() {}
+		if attemptTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, attemptTimeout)
+		}
+		res, err := runHarness(attemptCtx, prompt, target.Harness, targetArgs(cfg, target), logFile, retry, attemptRunner)
+		timedOut := (attemptCtx.Err() == context.DeadlineExceeded || isHarnessIdleTimeout(err)) && ctx.Err() == nil
+		cancel()
+		if err == nil || ctx.Err() != nil {
+			return res, target, events, err
+		}
+
+		reason := ""
+		if timedOut {
+			timeouts++
+			if cfg.FallbackOn.Timeouts <= 0 {
+				return res, target, events, err
+			}
+			if timeouts < cfg.FallbackOn.Timeouts {
+				fmt.Fprintf(os.Stderr, "Harness %s timed out (%d/%d); retrying\n", target.label(), timeouts, cfg.FallbackOn.Timeouts)
+				continue
+			}
+			reason = "timeout"
+		} else {
+			reason = classifyHarnessFailure(cfg.FallbackOn, res.RawOutput, err)
+		}
+		if reason == "" || idx+1 >= len(chain) {
+			return res, target, events, err
+		}
+
+		next := chain[idx+1]
+		event := harnessFallbackEvent{From: target.label(), To: next.label(), Reason: reason}
+		events = append(events, event)
+		writeLogEntry(logFile, logEntry{
+			Type:           "harness_fallback",
+			Mode:           mode,
+			Iteration:      iteration,
+			FromModel:      event.From,
+			ToModel:        event.To,
+			FallbackReason: event.Reason,
+			Cooldown:       cfg.FallbackCooldown,
+		})
+		fmt.Fprintf(os.Stderr, "Harness %s failed (%s); falling back to %s\n", event.From, event.Reason, event.To)
+		idx++
+		timeouts = 0
+		state.HarnessFallbackIndex = idx
+		state.HarnessFallbackCooldown = cfg.FallbackCooldown
+	}
+}
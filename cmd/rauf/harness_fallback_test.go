@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestClassifyHarnessFailure(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 3").Run()
+	policy := fallbackPolicy{ExitCodes: []int{3}, Patterns: []string{"Unauthorized"}}
+
+	if got := classifyHarnessFailure(policy, "", nil); got != "" {
+		t.Fatalf("expected no class for success, got %q", got)
+	}
+	if got := classifyHarnessFailure(policy, "", exitErr); got != "exit_code:3" {
+		t.Fatalf("exit code class = %q", got)
+	}
+	if got := classifyHarnessFailure(policy, "error: 401 unauthorized", errors.New("boom")); got != "pattern:Unauthorized" {
+		t.Fatalf("pattern class = %q", got)
+	}
+	if got := classifyHarnessFailure(policy, "", &exec.Error{Name: "missing", Err: exec.ErrNotFound}); got != "not_found" {
+		t.Fatalf("not found class = %q", got)
+	}
+	if got := classifyHarnessFailure(fallbackPolicy{}, "unauthorized", exitErr); got != "" {
+		t.Fatalf("expected empty policy to ignore failure, got %q", got)
+	}
+}
+
+func TestTickFallbackCooldown(t *testing.T) {
+	chain := []harnessTarget{{Harness: "claude"}, {Harness: "codex", Model: "gpt-5"}}
+	state := raufState{HarnessFallbackIndex: 1, HarnessFallbackCooldown: 1}
+
+	if _, ok := tickFallbackCooldown(&state, chain); ok || state.HarnessFallbackIndex != 1 {
+		t.Fatalf("expected to stay on fallback during cooldown, state=%+v", state)
+	}
+	event, ok := tickFallbackCooldown(&state, chain)
+	if !ok || state.HarnessFallbackIndex != 0 {
+		t.Fatalf("expected return to primary, state=%+v", state)
+	}
+	if event.From != "codex (gpt-5)" || event.To != "claude" || event.Reason != "cooldown_expired" {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+
+	stale := raufState{HarnessFallbackIndex: 5}
+	if _, ok := tickFallbackCooldown(&stale, chain); ok || stale.HarnessFallbackIndex != 0 {
+		t.Fatalf("expected out-of-range index to reset, state=%+v", stale)
+	}
+}
+
+func TestRunHarnessChainFallsBack(t *testing.T) {
+	orig := runHarnessOnce
+	defer func() { runHarnessOnce = orig }()
+
+	var calls []string
+	runHarnessOnce = func(ctx context.Context, prompt string, harness, harnessArgs string, logFile *os.File, runner runtimeExec) (string, error) {
+		calls = append(calls, harness+" "+harnessArgs)
+		switch harness {
+		case "primary":
+			return "Error: not authenticated\n", fmt.Errorf("exit status 1")
+		case "slow":
+			<-ctx.Done()
+			return "", ctx.Err()
+		default:
+			return "RAUF_COMPLETE\n", nil
+		}
+	}
+
+	cfg := runtimeConfig{
+		ModelFlag:        "--model",
+		FallbackOn:       fallbackPolicy{Patterns: []string{"not authenticated"}, Timeouts: 2},
+		FallbackCooldown: 2,
+	}
+	chain := []harnessTarget{{Harness: "primary"}, {Harness: "slow"}, {Harness: "backup", HarnessArgs: "exec", Model: "m1"}}
+	state := raufState{}
+	res, target, events, err := runHarnessChain(context.Background(), 20*time.Millisecond, "prompt", chain, cfg, &state, nil, retryConfig{}, runtimeExec{Quiet: true}, "build", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Harness != "backup" || res.Output != "RAUF_COMPLETE\n" {
+		t.Fatalf("unexpected target %+v result %+v", target, res)
+	}
+	want := []string{"primary ", "slow ", "slow ", "backup exec --model m1"}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Fatalf("calls = %q, want %q", calls, want)
+	}
+	if len(events) != 2 || events[0].Reason != "pattern:not authenticated" || events[1].Reason != "timeout" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if state.HarnessFallbackIndex != 2 || state.HarnessFallbackCooldown != 2 {
+		t.Fatalf("unexpected fallback state: %+v", state)
+	}
+}
+
+func TestRunHarnessChainStopsOnUnclassifiedFailure(t *testing.T) {
+	orig := runHarnessOnce
+	defer func() { runHarnessOnce = orig }()
+
+	calls := 0
+	runHarnessOnce = func(ctx context.Context, prompt string, harness, harnessArgs string, logFile *os.File, runner runtimeExec) (string, error) {
+		calls++
+		return "compile error\n", fmt.Errorf("exit status 2")
+	}
+
+	cfg := runtimeConfig{FallbackOn: fallbackPolicy{Patterns: []string{"quota"}}}
+	chain := []harnessTarget{{Harness: "primary"}, {Harness: "backup"}}
+	state := raufState{}
+	_, target, events, err := runHarnessChain(context.Background(), 0, "prompt", chain, cfg, &state, nil, retryConfig{}, runtimeExec{Quiet: true}, "build", 1)
+	if err == nil || target.Harness != "primary" || len(events) != 0 || calls != 1 {
+		t.Fatalf("expected primary failure without fallback, err=%v target=%+v events=%+v calls=%d", err, target, events, calls)
+	}
+}
+
+func TestParseConfigHarnessFallbacks(t *testing.T) {
+	cfg := runtimeConfig{}
+	data := "harness: claude\n" +
+		"harness_fallbacks:\n" +
+		"  - harness: codex\n    harness_args: \"exec --full-auto\"\n    model: gpt-5\n" +
+		"  - opencode\n" +
+		"fallback_on:\n  exit_codes: 1, 127\n  patterns: \"unauthorized,overloaded\"\n  timeouts: 2\n" +
+		"fallback_cooldown: 3\n"
+	if err := parseConfigBytes([]byte(data), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(cfg.HarnessFallbacks) != 2 {
+		t.Fatalf("expected 2 fallbacks, got %+v", cfg.HarnessFallbacks)
+	}
+	first := cfg.HarnessFallbacks[0]
+	if first.Harness != "codex" || first.HarnessArgs != "exec --full-auto" || first.Model != "gpt-5" {
+		t.Fatalf("unexpected first fallback: %+v", first)
+	}
+	if cfg.HarnessFallbacks[1].Harness != "opencode" {
+		t.Fatalf("unexpected second fallback: %+v", cfg.HarnessFallbacks[1])
+	}
+	if fmt.Sprint(cfg.FallbackOn.ExitCodes) != "[1 127]" || len(cfg.FallbackOn.Patterns) != 2 || cfg.FallbackOn.Timeouts != 2 {
+		t.Fatalf("unexpected fallback policy: %+v", cfg.FallbackOn)
+	}
+	if cfg.FallbackCooldown != 3 || cfg.Harness != "claude" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import "os"
+
+// Session reset reasons, recorded in harness_session log entries.
+const (
+	sessionResetTaskChanged  = "task_changed"
+	sessionResetModelChanged = "model_changed"
+	sessionResetVerifyPass   = "verify_pass"
+	sessionResetFailed       = "resume_failed"
+)
+
+// harnessSessionFor returns the session ID to resume for this iteration. A
+// stored session is dropped when the active task or the model has changed
+// since it was captured; the reset reason is returned so it can be logged.
+func harnessSessionFor(state *raufState, task, model string) (string, string) {
+	if state.SessionID == "" {
+		return "", ""
+	}
+	reason := ""
+	switch {
+	case state.SessionTask != task:
+		reason = sessionResetTaskChanged
+	case state.SessionModel != model:
+		reason = sessionResetModelChanged
+	}
+	if reason != "" {
+		clearHarnessSession(state)
+		return "", reason
+	}
+	return state.SessionID, ""
+}
+
+// recordHarnessSession stores the session reported by the harness. It returns
+// true when the ID is new, i.e. a fresh conversation was started.
+func recordHarnessSession(state *raufState, res harnessResult, target harnessTarget, task, model string) bool {
+	if res.SessionID == "" {
+		return false
+	}
+	changed := res.SessionID != state.SessionID
+	state.SessionID = res.SessionID
+	state.SessionTask = task
+	state.SessionHarness = target.Harness
+	state.SessionModel = model
+	return changed
+}
+
+func clearHarnessSession(state *raufState) {
+	state.SessionID = ""
+	state.SessionTask = ""
+	state.SessionHarness = ""
+	state.SessionModel = ""
+}
+
+func logHarnessSession(logFile *os.File, mode string, iteration int, action, sessionID, reason string) {
+	writeLogEntry(logFile, logEntry{
+		Type:          "harness_session",
+		Mode:          mode,
+		Iteration:     iteration,
+		SessionID:     sessionID,
+		SessionAction: action,
+		SessionReason: reason,
+	})
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestAdaptersCaptureSessionID(t *testing.T) {
+	tests := []struct {
+		adapter harnessAdapter
+		raw     string
+	}{
+		{claudeAdapter{}, `{"type":"system","subtype":"init","session_id":"s-1"}` + "\n" + `{"type":"result","result":"ok","session_id":"s-1"}`},
+		{codexAdapter{}, `{"type":"thread.started","thread_id":"s-1"}`},
+		{opencodeAdapter{}, `{"type":"text","sessionID":"s-1","part":{"text":"ok"}}`},
+		{opencodeAdapter{}, `{"type":"text","part":{"text":"ok","sessionID":"s-1"}}`},
+	}
+	for _, tc := range tests {
+		if got := parseHarnessOutput(tc.adapter, tc.raw).SessionID; got != "s-1" {
+			t.Errorf("%s: expected session s-1, got %q", tc.adapter.name(), got)
+		}
+	}
+}
+
+func TestAdapterResumeArgs(t *testing.T) {
+	tests := []struct {
+		adapter sessionAdapter
+		args    []string
+		want    []string
+	}{
+		{claudeAdapter{}, []string{"-p"}, []string{"-p", "--resume", "s-1"}},
+		{claudeAdapter{}, []string{"-p", "--continue"}, []string{"-p", "--continue"}},
+		{codexAdapter{}, []string{"exec", "--json"}, []string{"exec", "--json", "resume", "s-1"}},
+		{opencodeAdapter{}, []string{"run"}, []string{"run", "--session", "s-1"}},
+	}
+	for _, tc := range tests {
+		if got := tc.adapter.resumeArgs(tc.args, "s-1"); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("resumeArgs(%v) = %v, want %v", tc.args, got, tc.want)
+		}
+	}
+}
+
+func TestHarnessSessionFor(t *testing.T) {
+	state := raufState{}
+	res := harnessResult{SessionID: "s-1"}
+	if !recordHarnessSession(&state, res, harnessTarget{Harness: "claude"}, "T1: a", "sonnet") {
+		t.Fatalf("expected new session to be recorded")
+	}
+	if recordHarnessSession(&state, res, harnessTarget{Harness: "claude"}, "T1: a", "sonnet") {
+		t.Fatalf("same session must not be reported as new")
+	}
+	if id, reason := harnessSessionFor(&state, "T1: a", "sonnet"); id != "s-1" || reason != "" {
+		t.Fatalf("expected resume, got %q %q", id, reason)
+	}
+	if id, reason := harnessSessionFor(&state, "T1: a", "opus"); id != "" || reason != sessionResetModelChanged {
+		t.Fatalf("expected model reset, got %q %q", id, reason)
+	}
+	if state.SessionID != "" {
+		t.Fatalf("expected session to be cleared: %+v", state)
+	}
+
+	recordHarnessSession(&state, res, harnessTarget{Harness: "claude"}, "T1: a", "opus")
+	if id, reason := harnessSessionFor(&state, "T2: b", "opus"); id != "" || reason != sessionResetTaskChanged {
+		t.Fatalf("expected task reset, got %q %q", id, reason)
+	}
+	if id, reason := harnessSessionFor(&state, "T2: b", "opus"); id != "" || reason != "" {
+		t.Fatalf("expected no session, got %q %q", id, reason)
+	}
+}
+
+func TestRunHarnessResumesSession(t *testing.T) {
+	orig := execCommand
+	defer func() { execCommand = orig }()
+
+	var gotArgs []string
+	execCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		gotArgs = args
+		return exec.CommandContext(ctx, "echo", `{"type":"result","result":"ok","session_id":"s-1"}`)
+	}
+	runner := runtimeExec{Quiet: true, Adapter: claudeAdapter{}, SessionID: "s-1"}
+	res, err := runHarness(context.Background(), "prompt", "claude", "-p", nil, retryConfig{}, runner)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(gotArgs[len(gotArgs)-2:], []string{"--resume", "s-1"}) {
+		t.Fatalf("expected resume flag, got %v", gotArgs)
+	}
+	if res.SessionID != "s-1" {
+		t.Fatalf("expected session in result, got %+v", res)
+	}
+}
+
+func TestParseConfigSessionReuse(t *testing.T) {
+	cfg := runtimeConfig{}
+	if err := parseConfigBytes([]byte("session_reuse: true\n"), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if !cfg.SessionReuse {
+		t.Fatalf("expected session reuse to be enabled")
+	}
+}
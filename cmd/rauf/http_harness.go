@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// httpHarnessName is the built-in harness that posts the prompt to an
+// OpenAI-compatible chat completions endpoint instead of running a CLI.
+const httpHarnessName = "http"
+
+const defaultHTTPBaseURL = "https://api.openai.com/v1"
+const defaultHTTPAPIKeyEnv = "OPENAI_API_KEY"
+
+// httpFileBlockInstructions tells the model how to produce files, since it
+// cannot run tools. Blocks are applied by applyFileBlocks.
+const httpFileBlockInstructions = "You cannot run tools or commands. To create or replace a file, output its full content " +
+	"in a fenced code block whose info string is file=<relative path>, for example:\n" +
+	"```markdown file=specs/example.md\n...\n```"
+
+type httpHarnessConfig struct {
+	BaseURL   string
+	APIKeyEnv string
+	// Model is used when harness_args has no model flag.
+	Model     string
+	ModelFlag string
+}
+
+var httpClient = &http.Client{}
+
+func isHTTPHarness(harness string) bool {
+	return harness == httpHarnessName
+}
+
+// httpModel picks the model from harness_args (where model escalation puts
+// it), falling back to the configured model.
+func httpModel(cfg httpHarnessConfig, harnessArgs string) string {
+	flag := cfg.ModelFlag
+	if flag == "" {
+		flag = "--model"
+	}
+	args, _ := splitArgs(harnessArgs)
+	model := ""
+	for i, arg := range args {
+		if arg == flag && i+1 < len(args) {
+			model = args[i+1]
+		} else if strings.HasPrefix(arg, flag+"=") {
+			model = strings.TrimPrefix(arg, flag+"=")
+		}
+	}
+	return firstNonEmpty(model, cfg.Model)
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+}
+
+type chatChoice struct {
+	Delta   chatMessage `json:"delta"`
+	Message chatMessage `json:"message"`
+}
+
+type chatResponse struct {
+	Choices []chatChoice `json:"choices"`
+}
+
+// runHTTPHarness sends the prompt to the chat completions endpoint, streams
+// the reply to the log and stdout, and applies any fenced file blocks.
+func runHTTPHarness(ctx context.Context, prompt, harnessArgs string, logFile *os.File, runner runtimeExec) (string, error) {
+	cfg := runner.HTTP
+	model := httpModel(cfg, harnessArgs)
+	if model == "" {
+		return "", fmt.Errorf("http harness requires a model (http_model, model_default or %s in harness_args)", firstNonEmpty(cfg.ModelFlag, "--model"))
+	}
+	body, err := json.Marshal(chatRequest{
+		Model: model,
+		Messages: []chatMessage{
+			{Role: "system", Content: httpFileBlockInstructions},
+			{Role: "user", Content: prompt},
+		},
+		Stream: true,
+	})
+	if err != nil {
+		return "", err
+	}
+	url := strings.TrimRight(firstNonEmpty(cfg.BaseURL, defaultHTTPBaseURL), "/") + "/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if key := os.Getenv(firstNonEmpty(cfg.APIKeyEnv, defaultHTTPAPIKeyEnv)); key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var out io.Writer = io.Discard
+	if logFile != nil {
+		out = logFile
+	}
+	if !runner.Quiet {
+		out = io.MultiWriter(out, newFilteringWriter(os.Stdout, "RAUF_QUESTION:"))
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		output := fmt.Sprintf("HTTP %d: %s\n", resp.StatusCode, strings.TrimSpace(string(data)))
+		if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+			output += "Retry-After: " + retryAfter + "\n"
+		}
+		_, _ = io.WriteString(out, output)
+		err := fmt.Errorf("http harness: %s returned status %d", url, resp.StatusCode)
+		return output, &harnessRunError{err: err, stderr: output}
+	}
+
+	buffer := &limitedBuffer{max: 1024 * 1024}
+	w := io.MultiWriter(out, buffer)
+	if strings.Contains(resp.Header.Get("Content-Type"), "text/event-stream") {
+		err = readChatStream(resp.Body, w)
+	} else {
+		err = readChatResponse(resp.Body, w)
+	}
+	output := buffer.String()
+	if err != nil {
+		return output, err
+	}
+	if !strings.HasSuffix(output, "\n") {
+		_, _ = io.WriteString(out, "\n")
+	}
+
+	written, err := applyFileBlocks(runner.WorkDir, output)
+	for _, path := range written {
+		fmt.Fprintf(os.Stderr, "http harness: wrote %s\n", path)
+	}
+	return output, err
+}
+
+// readChatStream copies content deltas from a server-sent event stream.
+func readChatStream(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+		var chunk chatResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			continue
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				if _, err := io.WriteString(w, choice.Delta.Content); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// readChatResponse handles servers that ignore "stream" and reply with a
+// single JSON document.
+func readChatResponse(r io.Reader, w io.Writer) error {
+	var resp chatResponse
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		return fmt.Errorf("http harness: invalid response: %w", err)
+	}
+	for _, choice := range resp.Choices {
+		if _, err := io.WriteString(w, choice.Message.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileBlockPath returns the target path from a fence info string such as
+// "markdown file=specs/a.md", "path=specs/a.md" or "file:specs/a.md".
+func fileBlockPath(info string) string {
+	for _, field := range strings.Fields(info) {
+		for _, prefix := range []string{"file=", "path=", "file:"} {
+			if strings.HasPrefix(field, prefix) {
+				return stripQuotes(strings.TrimPrefix(field, prefix))
+			}
+		}
+	}
+	return ""
+}
+
+// applyFileBlocks writes every fenced block tagged with a file path into dir
+// and returns the paths written.
+func applyFileBlocks(dir, output string) ([]string, error) {
+	var written []string
+	lines := strings.Split(output, "\n")
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if len(trimmed) < 3 || (trimmed[0] != '`' && trimmed[0] != '~') {
+			continue
+		}
+		fenceChar := trimmed[0]
+		fenceLen := countLeadingChars(trimmed, fenceChar)
+		if fenceLen < 3 {
+			continue
+		}
+		path := fileBlockPath(trimmed[fenceLen:])
+		var content []string
+		closed := false
+		j := i + 1
+		for ; j < len(lines); j++ {
+			t := strings.TrimSpace(lines[j])
+			if len(t) >= fenceLen && t[0] == fenceChar && countLeadingChars(t, fenceChar) == len(t) && len(t) >= fenceLen {
+				closed = true
+				break
+			}
+			content = append(content, lines[j])
+		}
+		i = j
+		if path == "" || !closed {
+			continue
+		}
+		text := strings.Join(content, "\n")
+		if text != "" {
+			text += "\n"
+		}
+		if err := writeWorkspaceFile(dir, path, text, false); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHTTPModel(t *testing.T) {
+	cfg := httpHarnessConfig{Model: "base", ModelFlag: "--model"}
+	if got := httpModel(cfg, ""); got != "base" {
+		t.Fatalf("expected configured model, got %q", got)
+	}
+	if got := httpModel(cfg, "--model strong"); got != "strong" {
+		t.Fatalf("expected model from args, got %q", got)
+	}
+	if got := httpModel(httpHarnessConfig{ModelFlag: "-m"}, "-m=gpt-x"); got != "gpt-x" {
+		t.Fatalf("expected model from -m=, got %q", got)
+	}
+}
+
+func TestApplyFileBlocks(t *testing.T) {
+	dir := t.TempDir()
+	chdirTemp(t, dir)
+
+	output := "Here is the spec.\n" +
+		"````markdown file=specs/auth.md\n# Auth\n\n```go\nx := 1\n```\n````\n" +
+		"```go\nignored()\n```\n" +
+		"~~~ path=IMPLEMENTATION_PLAN.md\n- [ ] T1: do it\n~~~\n" +
+		"```text file=../escape.txt\nnope\n```\n"
+	written, err := applyFileBlocks("", output)
+	if err == nil {
+		t.Fatalf("expected error for path outside the workspace")
+	}
+	if strings.Join(written, ",") != "specs/auth.md,IMPLEMENTATION_PLAN.md" {
+		t.Fatalf("unexpected written files: %v", written)
+	}
+	spec, _ := os.ReadFile("specs/auth.md")
+	if string(spec) != "# Auth\n\n```go\nx := 1\n```\n" {
+		t.Fatalf("unexpected spec content: %q", spec)
+	}
+	plan, _ := os.ReadFile("IMPLEMENTATION_PLAN.md")
+	if string(plan) != "- [ ] T1: do it\n" {
+		t.Fatalf("unexpected plan content: %q", plan)
+	}
+}
+
+func TestRunHTTPHarnessStreaming(t *testing.T) {
+	dir := t.TempDir()
+	chdirTemp(t, dir)
+	t.Setenv("RAUF_TEST_API_KEY", "secret")
+
+	var got chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, piece := range []string{"Writing spec.\n", "```markdown file=specs/a.md\n# A\n", "```\nRAUF_COMPLETE"} {
+			data, _ := json.Marshal(map[string]interface{}{
+				"choices": []map[string]interface{}{{"delta": map[string]string{"content": piece}}},
+			})
+			fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	runner := runtimeExec{Quiet: true, HTTP: httpHarnessConfig{BaseURL: server.URL + "/v1/", APIKeyEnv: "RAUF_TEST_API_KEY", Model: "base"}}
+	out, err := runHarnessOnce(context.Background(), "write a spec", httpHarnessName, "--model strong", nil, runner)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Model != "strong" || !got.Stream || len(got.Messages) != 2 || got.Messages[1].Content != "write a spec" {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+	if !hasCompletionSentinel(out) {
+		t.Fatalf("expected completion sentinel in output %q", out)
+	}
+	spec, err := os.ReadFile("specs/a.md")
+	if err != nil || string(spec) != "# A\n" {
+		t.Fatalf("expected spec file to be written, got %q err=%v", spec, err)
+	}
+}
+
+func TestRunHTTPHarnessJSONAndErrors(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			http.Error(w, `{"error":"rate limit exceeded"}`, status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"plain reply"}}]}`)
+	}))
+	defer server.Close()
+
+	runner := runtimeExec{Quiet: true, HTTP: httpHarnessConfig{BaseURL: server.URL, Model: "m"}}
+	out, err := runHTTPHarness(context.Background(), "prompt", "", nil, runner)
+	if err != nil || out != "plain reply" {
+		t.Fatalf("unexpected result: out=%q err=%v", out, err)
+	}
+
+	status = http.StatusTooManyRequests
+	out, err = runHTTPHarness(context.Background(), "prompt", "", nil, runner)
+	if err == nil || !strings.Contains(out, "429") || !strings.Contains(out, "rate limit") {
+		t.Fatalf("expected retryable error output, got out=%q err=%v", out, err)
+	}
+
+	if _, err := runHTTPHarness(context.Background(), "prompt", "", nil, runtimeExec{Quiet: true}); err == nil {
+		t.Fatalf("expected error without a model")
+	}
+}
+
+func TestParseConfigHTTPHarness(t *testing.T) {
+	cfg := runtimeConfig{}
+	data := "harness: http\nhttp_base_url: http://localhost:8080/v1\nhttp_api_key_env: LOCAL_KEY\nhttp_model: llama3\n"
+	if err := parseConfigBytes([]byte(data), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if cfg.HTTPBaseURL != "http://localhost:8080/v1" || cfg.HTTPAPIKeyEnv != "LOCAL_KEY" || cfg.HTTPModel != "llama3" {
+		t.Fatalf("unexpected http config: %+v", cfg)
+	}
+}
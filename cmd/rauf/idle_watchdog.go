@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"sync/atomic"
+	"time"
+)
+
+// harnessIdleReason is the exit and retry reason for harnesses stopped by the
+// idle watchdog.
+const harnessIdleReason = "harness_idle_timeout"
+
+// defaultIdleKillGrace is how long a stalled harness gets between SIGTERM and
+// SIGKILL.
+const defaultIdleKillGrace = 10 * time.Second
+
+// harnessIdleError reports a harness killed for producing no output.
+type harnessIdleError struct {
+	idle time.Duration
+}
+
+func (e *harnessIdleError) Error() string {
+	return fmt.Sprintf("%s: no output for %s", harnessIdleReason, e.idle)
+}
+
+func isHarnessIdleTimeout(err error) bool {
+	var idleErr *harnessIdleError
+	return errors.As(err, &idleErr)
+}
+
+// idleWatchdog sits next to the harness stdout/stderr writers; every write
+// resets the idle clock.
+type idleWatchdog struct {
+	timeout time.Duration
+	grace   time.Duration
+	last    atomic.Int64
+	fired   atomic.Bool
+}
+
+func newIdleWatchdog(timeout, grace time.Duration) *idleWatchdog {
+	if grace <= 0 {
+		grace = defaultIdleKillGrace
+	}
+	w := &idleWatchdog{timeout: timeout, grace: grace}
+	w.last.Store(time.Now().UnixNano())
+	return w
+}
+
+func (w *idleWatchdog) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		w.last.Store(time.Now().UnixNano())
+	}
+	return len(p), nil
+}
+
+// run starts cmd and waits for it. When no output arrives for the timeout,
+// the process group gets SIGTERM, then SIGKILL after the grace period, and a
+// harnessIdleError is returned. A nil watchdog just runs cmd.
+func (w *idleWatchdog) run(cmd *exec.Cmd) error {
+	if w == nil {
+		return cmd.Run()
+	}
+	setProcessGroup(cmd)
+	// Grandchildren holding the output pipes must not keep Wait blocked.
+	cmd.WaitDelay = w.grace
+	w.last.Store(time.Now().UnixNano())
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	go w.watch(cmd, done)
+	err := cmd.Wait()
+	close(done)
+	if w.fired.Load() {
+		return &harnessIdleError{idle: w.timeout}
+	}
+	return err
+}
+
+func (w *idleWatchdog) watch(cmd *exec.Cmd, done <-chan struct{}) {
+	interval := w.timeout / 4
+	if interval > time.Second {
+		interval = time.Second
+	}
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		if time.Since(time.Unix(0, w.last.Load())) < w.timeout {
+			continue
+		}
+		w.fired.Store(true)
+		_ = terminateProcessGroup(cmd, false)
+		select {
+		case <-done:
+		case <-time.After(w.grace):
+			_ = terminateProcessGroup(cmd, true)
+		}
+		return
+	}
+}
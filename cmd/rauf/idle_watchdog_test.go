@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunHarnessOnceIdleTimeout(t *testing.T) {
+	orig := execCommand
+	defer func() { execCommand = orig }()
+	execCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		// The background sleep stands in for a grandchild that holds stdout open.
+		return exec.CommandContext(ctx, "sh", "-c", "echo start; (sleep 30; echo late) & wait")
+	}
+
+	runner := runtimeExec{Quiet: true, IdleTimeout: 100 * time.Millisecond, IdleKillGrace: 100 * time.Millisecond}
+	start := time.Now()
+	out, err := runHarnessOnce(context.Background(), "prompt", "harness", "", nil, runner)
+	if !isHarnessIdleTimeout(err) {
+		t.Fatalf("expected idle timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("watchdog took too long: %s", elapsed)
+	}
+	if !strings.Contains(out, "start") || strings.Contains(out, "late") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestRunHarnessOnceIdleTimeoutKeepsActiveHarness(t *testing.T) {
+	orig := execCommand
+	defer func() { execCommand = orig }()
+	execCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, "sh", "-c", "for i in 1 2 3 4 5 6; do echo $i; sleep 0.05; done")
+	}
+
+	runner := runtimeExec{Quiet: true, IdleTimeout: 300 * time.Millisecond}
+	out, err := runHarnessOnce(context.Background(), "prompt", "harness", "", nil, runner)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "6") {
+		t.Fatalf("expected full output, got %q", out)
+	}
+}
+
+func TestRunHarnessRetriesIdleTimeout(t *testing.T) {
+	orig := runHarnessOnce
+	defer func() { runHarnessOnce = orig }()
+	calls := 0
+	runHarnessOnce = func(ctx context.Context, prompt string, harness, harnessArgs string, logFile *os.File, runner runtimeExec) (string, error) {
+		calls++
+		if calls == 1 {
+			return "", &harnessRunError{err: &harnessIdleError{idle: time.Minute}}
+		}
+		return "ok", nil
+	}
+
+	retry := retryConfig{Enabled: true, MaxAttempts: 1, BackoffBase: time.Millisecond, Match: []string{"rate limit"}}
+	res, err := runHarness(context.Background(), "prompt", "harness", "", nil, retry, runtimeExec{Quiet: true})
+	if err != nil || res.RetryCount != 1 || res.RetryReason != harnessIdleReason {
+		t.Fatalf("unexpected result: %+v err=%v", res, err)
+	}
+}
+
+func TestParseConfigIdleTimeout(t *testing.T) {
+	cfg := runtimeConfig{}
+	if err := parseConfigBytes([]byte("idle_timeout: 5m\nidle_kill_grace: 3s\n"), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if cfg.IdleTimeout != 5*time.Minute || cfg.IdleKillGrace != 3*time.Second {
+		t.Fatalf("unexpected idle config: %s %s", cfg.IdleTimeout, cfg.IdleKillGrace)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// interruptExitReason marks an iteration stopped by SIGINT/SIGTERM.
+const interruptExitReason = "interrupted"
+
+type interruptKey struct{}
+
+// interruptController implements two-stage interrupt handling. The first
+// SIGINT/SIGTERM lets the current iteration finish (verify, commit, save
+// state) and stops the loop afterwards; the second cancels the context so
+// running harness and verify process groups are terminated.
+type interruptController struct {
+	requested atomic.Bool
+	cancel    context.CancelFunc
+	signals   chan os.Signal
+	done      chan struct{}
+}
+
+func watchInterrupts(parent context.Context) (context.Context, *interruptController) {
+	ctx, cancel := context.WithCancel(parent)
+	c := &interruptController{
+		cancel:  cancel,
+		signals: make(chan os.Signal, 2),
+		done:    make(chan struct{}),
+	}
+	ctx = context.WithValue(ctx, interruptKey{}, c)
+	signal.Notify(c.signals, os.Interrupt, syscall.SIGTERM)
+	go c.loop(ctx)
+	return ctx, c
+}
+
+func (c *interruptController) loop(ctx context.Context) {
+	// Once the loop ends, Ctrl-C gets its default behaviour back so a hung
+	// process can still be killed from the terminal.
+	defer signal.Stop(c.signals)
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ctx.Done():
+			return
+		case <-c.signals:
+		}
+		if c.requested.CompareAndSwap(false, true) {
+			fmt.Fprintln(os.Stderr, "\nInterrupt received; finishing the current iteration. Press Ctrl-C again to abort.")
+			continue
+		}
+		fmt.Fprintln(os.Stderr, "\nSecond interrupt; aborting.")
+		c.cancel()
+		return
+	}
+}
+
+func (c *interruptController) stop() {
+	signal.Stop(c.signals)
+	close(c.done)
+	c.cancel()
+}
+
+// interruptRequested reports whether a first-stage interrupt asked the loop
+// to stop after the current iteration.
+func interruptRequested(ctx context.Context) bool {
+	c, ok := ctx.Value(interruptKey{}).(*interruptController)
+	return ok && c.requested.Load()
+}
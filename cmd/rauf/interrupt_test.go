@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunModeStopsAfterInterruptedIteration(t *testing.T) {
+	dir := t.TempDir()
+	chdirTemp(t, dir)
+	if err := os.WriteFile("PROMPT_plan.md", []byte("plan"), 0o644); err != nil {
+		t.Fatalf("write prompt failed: %v", err)
+	}
+
+	interrupts := &interruptController{}
+	ctx := context.WithValue(context.Background(), interruptKey{}, interrupts)
+
+	orig := runHarnessOnce
+	defer func() { runHarnessOnce = orig }()
+	calls := 0
+	runHarnessOnce = func(ctx context.Context, prompt string, harness, harnessArgs string, logFile *os.File, runner runtimeExec) (string, error) {
+		calls++
+		interrupts.requested.Store(true) // Ctrl-C while the harness runs
+		if ctx.Err() != nil {
+			t.Fatalf("first interrupt must not cancel the harness")
+		}
+		return "working\n", nil
+	}
+
+	cfg := modeConfig{mode: "plan", promptFile: "PROMPT_plan.md", maxIterations: 5}
+	fileCfg := runtimeConfig{NoProgressIters: 10}
+	report := &RunReport{}
+	res, err := runMode(ctx, cfg, fileCfg, runtimeExec{Quiet: true}, raufState{}, false, "", "IMPLEMENTATION_PLAN.md", "harness", "", true, "logs", false, 0, 0, 0, false, nil, 0, nil, nil, report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.ExitReason != interruptExitReason || calls != 1 {
+		t.Fatalf("expected stop after one iteration, got reason=%q calls=%d", res.ExitReason, calls)
+	}
+	if len(report.Iterations) != 1 || report.Iterations[0].ExitReason != interruptExitReason {
+		t.Fatalf("unexpected iteration stats: %+v", report.Iterations)
+	}
+
+	logs, _ := filepath.Glob(filepath.Join("logs", "*.jsonl"))
+	if len(logs) != 1 {
+		t.Fatalf("expected one log file, got %v", logs)
+	}
+	data, _ := os.ReadFile(logs[0])
+	if !strings.Contains(string(data), `"type":"iteration_end"`) || !strings.Contains(string(data), `"exit_reason":"interrupted"`) {
+		t.Fatalf("expected interrupted iteration_end entry, got %s", data)
+	}
+	if _, err := os.Stat(statePath()); err != nil {
+		t.Fatalf("expected state to be saved: %v", err)
+	}
+}
+
+func TestInterruptControllerSecondSignalAborts(t *testing.T) {
+	ctx, c := watchInterrupts(context.Background())
+	defer c.stop()
+
+	c.signals <- os.Interrupt
+	time.Sleep(20 * time.Millisecond)
+	if !interruptRequested(ctx) || ctx.Err() != nil {
+		t.Fatalf("first interrupt must only request a stop")
+	}
+	c.signals <- os.Interrupt
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("second interrupt must cancel the context")
+	}
+}
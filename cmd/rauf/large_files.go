@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// binarySniffLen matches git's heuristic: a NUL byte in the first 8000 bytes marks a file as binary.
+const binarySniffLen = 8000
+
+type oversizedFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Binary bool   `json:"binary,omitempty"`
+}
+
+// fileStamp records enough about a file to notice modifications without git.
+type fileStamp struct {
+	Size    int64
+	ModTime int64
+}
+
+func largeFileGuardEnabled(cfg runtimeConfig) bool {
+	return cfg.MaxFileSize > 0 || cfg.BlockBinaryFiles
+}
+
+// parseByteSize accepts plain byte counts or values with a KB/MB/GB suffix (base 1024).
+func parseByteSize(value string) (int64, bool) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		return 0, false
+	}
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{
+		{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	} {
+		if strings.HasSuffix(value, unit.suffix) {
+			multiplier = unit.size
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			break
+		}
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n * multiplier, true
+}
+
+func formatByteSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+func isBinaryContent(data []byte) bool {
+	if len(data) > binarySniffLen {
+		data = data[:binarySniffLen]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// largeFileAllowlisted reports whether path matches one of the allowlist globs.
+func largeFileAllowlisted(path string, allowlist []string) bool {
+	for _, entry := range allowlist {
+		entry = strings.TrimSpace(entry)
+		if entry != "" && scopeEntryMatches(entry, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFileContent applies the size and binary limits to a single file.
+func checkFileContent(cfg runtimeConfig, path string, size int64, head []byte) (oversizedFile, bool) {
+	if largeFileAllowlisted(path, cfg.LargeFileAllowlist) {
+		return oversizedFile{}, false
+	}
+	binary := cfg.BlockBinaryFiles && isBinaryContent(head)
+	if binary || (cfg.MaxFileSize > 0 && size > cfg.MaxFileSize) {
+		return oversizedFile{Path: filepath.ToSlash(path), Size: size, Binary: binary}, true
+	}
+	return oversizedFile{}, false
+}
+
+// findLargeFilesGit inspects files changed between headBefore and headAfter.
+// Committed content is read from headAfter; uncommitted changes from the working tree.
+func findLargeFilesGit(cfg runtimeConfig, headBefore, headAfter string) ([]oversizedFile, bool) {
+	files, gitErr := listChangedFiles(headBefore, headAfter)
+	if gitErr {
+		return nil, true
+	}
+	found := []oversizedFile{}
+	for _, file := range files {
+		if headAfter != headBefore {
+			if hit, ok := checkFileAtRevision(cfg, headAfter, file); ok {
+				found = append(found, hit)
+			}
+			continue
+		}
+		found = append(found, findLargeFilesOnDisk(cfg, file)...)
+	}
+	return found, false
+}
+
+// checkFileAtRevision checks a committed file without loading the blob: the
+// size comes from `git cat-file -s` and only the first binarySniffLen bytes are
+// read for the binary check.
+func checkFileAtRevision(cfg runtimeConfig, rev, path string) (oversizedFile, bool) {
+	object := rev + ":" + filepath.ToSlash(path)
+	sizeText, err := gitStdout("cat-file", "-s", object)
+	if err != nil {
+		// Deleted in rev: nothing to push.
+		return oversizedFile{}, false
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(sizeText), 10, 64)
+	if err != nil {
+		return oversizedFile{}, false
+	}
+	var head []byte
+	if cfg.BlockBinaryFiles {
+		head, _ = gitBlobHead(object, binarySniffLen)
+	}
+	return checkFileContent(cfg, path, size, head)
+}
+
+// gitBlobHead reads at most n bytes of a blob from git's stdout.
+func gitBlobHead(object string, n int) ([]byte, error) {
+	cmd := exec.Command("git", "cat-file", "blob", object)
+	cmd.Env = os.Environ()
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	head, err := io.ReadAll(io.LimitReader(stdout, int64(n)))
+	// Stop git instead of draining the rest of a large blob.
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+	return head, err
+}
+
+// findLargeFilesOnDisk checks a working tree path, descending into directories
+// (git status reports untracked directories as a single entry).
+func findLargeFilesOnDisk(cfg runtimeConfig, path string) []oversizedFile {
+	found := []oversizedFile{}
+	_ = filepath.WalkDir(filepath.Clean(path), func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if hit, ok := checkFileOnDisk(cfg, p); ok {
+			found = append(found, hit)
+		}
+		return nil
+	})
+	return found
+}
+
+func checkFileOnDisk(cfg runtimeConfig, path string) (oversizedFile, bool) {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return oversizedFile{}, false
+	}
+	var head []byte
+	if cfg.BlockBinaryFiles {
+		f, err := os.Open(path)
+		if err == nil {
+			buf := make([]byte, binarySniffLen)
+			n, _ := f.Read(buf)
+			head = buf[:n]
+			f.Close()
+		}
+	}
+	return checkFileContent(cfg, path, info.Size(), head)
+}
+
+// workspaceStamps walks root like workspaceFingerprint and records size and
+// modification time per file, for no-git change detection.
+func workspaceStamps(root string, excludeDirs []string) map[string]fileStamp {
+	stamps := map[string]fileStamp{}
+	excludeDirAbs := make([]string, 0, len(excludeDirs))
+	for _, dir := range excludeDirs {
+		dir = strings.TrimSpace(dir)
+		if dir == "" {
+			continue
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(root, dir)
+		}
+		excludeDirAbs = append(excludeDirAbs, filepath.Clean(dir))
+	}
+	_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		cleanPath := filepath.Clean(path)
+		for _, dir := range excludeDirAbs {
+			if cleanPath == dir || strings.HasPrefix(cleanPath, dir+string(filepath.Separator)) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		stamps[rel] = fileStamp{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+		return nil
+	})
+	return stamps
+}
+
+// findLargeFilesNoGit checks files that are new or modified since the before snapshot.
+func findLargeFilesNoGit(cfg runtimeConfig, root string, before, after map[string]fileStamp) []oversizedFile {
+	changed := make([]string, 0)
+	for path, stamp := range after {
+		if prev, ok := before[path]; ok && prev == stamp {
+			continue
+		}
+		changed = append(changed, path)
+	}
+	sort.Strings(changed)
+	found := []oversizedFile{}
+	for _, path := range changed {
+		if hit, ok := checkFileOnDisk(cfg, filepath.Join(root, path)); ok {
+			hit.Path = filepath.ToSlash(path)
+			found = append(found, hit)
+		}
+	}
+	return found
+}
+
+// enforceLargeFileGuardrail turns detected files into a guardrail outcome,
+// a reason code and a listing of offending paths with sizes.
+func enforceLargeFileGuardrail(found []oversizedFile) (bool, string, string) {
+	if len(found) == 0 {
+		return true, "", ""
+	}
+	lines := make([]string, 0, len(found))
+	for _, f := range found {
+		kind := "too large"
+		if f.Binary {
+			kind = "binary"
+		}
+		lines = append(lines, fmt.Sprintf("- %s (%s, %s)", f.Path, formatByteSize(f.Size), kind))
+	}
+	reason := "large_file:" + found[0].Path
+	if found[0].Binary {
+		reason = "binary_file:" + found[0].Path
+	}
+	return false, reason, strings.Join(lines, "\n")
+}
+
+func reportLargeFiles(logFile *os.File, mode string, iteration int, found []oversizedFile, message string) {
+	if len(found) == 0 {
+		return
+	}
+	writeLogEntry(logFile, logEntry{
+		Type:       "large_files",
+		Mode:       mode,
+		Iteration:  iteration,
+		LargeFiles: found,
+	})
+	fmt.Fprintf(os.Stderr, "Large/binary files detected:\n%s\n", message)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := map[string]int64{
+		"1024":   1024,
+		"500KB":  500 << 10,
+		"2mb":    2 << 20,
+		"1 GB":   1 << 30,
+		"10k":    10 << 10,
+		"100B":   100,
+		"0":      0,
+		"-1":     -1,
+		"lots":   -1,
+		"1.5MB":  -1,
+		"":       -1,
+		"   5  ": 5,
+	}
+	for input, want := range tests {
+		got, ok := parseByteSize(input)
+		if want < 0 {
+			if ok {
+				t.Errorf("parseByteSize(%q) = %d, expected failure", input, got)
+			}
+			continue
+		}
+		if !ok || got != want {
+			t.Errorf("parseByteSize(%q) = %d, %t; want %d", input, got, ok, want)
+		}
+	}
+}
+
+func TestFindLargeFilesGit(t *testing.T) {
+	repoDir := t.TempDir()
+	head := initGitRepo(t, repoDir)
+	chdirTemp(t, repoDir)
+
+	if err := os.WriteFile(filepath.Join(repoDir, "big.txt"), []byte(strings.Repeat("a", 2048)), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(repoDir, "bin"), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "bin", "tool"), []byte("ELF\x00\x01"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "fixture.png"), []byte("PNG\x00"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	cfg := runtimeConfig{MaxFileSize: 1024, BlockBinaryFiles: true, LargeFileAllowlist: []string{"*.png"}}
+
+	// Uncommitted: the untracked directory is walked from the working tree.
+	found, gitErr := findLargeFilesGit(cfg, head, head)
+	if gitErr {
+		t.Fatalf("unexpected git error")
+	}
+	paths := []string{}
+	for _, f := range found {
+		paths = append(paths, f.Path)
+	}
+	if strings.Join(paths, ",") != "big.txt,bin/tool" {
+		t.Fatalf("unexpected working tree findings: %v", paths)
+	}
+
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-m", "add files")
+	after := runGit(t, repoDir, "rev-parse", "HEAD")
+
+	// Tracing makes every git command write to stderr; sizes must not change.
+	t.Setenv("GIT_TRACE", "1")
+	found, gitErr = findLargeFilesGit(cfg, head, after)
+	if gitErr || len(found) != 2 {
+		t.Fatalf("unexpected committed findings: %+v (gitErr=%t)", found, gitErr)
+	}
+	ok, reason, message := enforceLargeFileGuardrail(found)
+	if ok || reason != "large_file:big.txt" {
+		t.Fatalf("expected large_file:big.txt, got ok=%t reason=%s", ok, reason)
+	}
+	if !strings.Contains(message, "- big.txt (2.0 KB, too large)") || !strings.Contains(message, "- bin/tool (5 B, binary)") {
+		t.Fatalf("unexpected message: %q", message)
+	}
+}
+
+func TestFindLargeFilesNoGit(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "existing.bin"), []byte("old\x00"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "logs"), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	before := workspaceStamps(dir, []string{"logs"})
+
+	if err := os.WriteFile(filepath.Join(dir, "coverage.out"), []byte(strings.Repeat("x", 300)), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "logs", "run.jsonl"), []byte(strings.Repeat("x", 300)), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	after := workspaceStamps(dir, []string{"logs"})
+
+	cfg := runtimeConfig{MaxFileSize: 100, BlockBinaryFiles: true}
+	found := findLargeFilesNoGit(cfg, dir, before, after)
+	if len(found) != 1 || found[0].Path != "coverage.out" || found[0].Size != 300 {
+		t.Fatalf("expected only the new coverage file, got %+v", found)
+	}
+}
+
+func TestParseConfigLargeFiles(t *testing.T) {
+	cfg := runtimeConfig{}
+	data := "max_file_size: 5MB\nblock_binary_files: true\nlarge_file_allowlist:\n  - \"assets/*.png\"\n  - docs/\n"
+	if err := parseConfigBytes([]byte(data), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if cfg.MaxFileSize != 5<<20 || !cfg.BlockBinaryFiles {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if len(cfg.LargeFileAllowlist) != 2 || cfg.LargeFileAllowlist[0] != "assets/*.png" {
+		t.Fatalf("unexpected allowlist: %v", cfg.LargeFileAllowlist)
+	}
+}
@@ -22,14 +22,61 @@ type logEntry struct {
 	CompletionSignal    string   `json:"completion_signal,omitempty"`
 	CompletionSpecs     []string `json:"completion_specs,omitempty"`
 	CompletionArtifacts []string `json:"completion_artifacts,omitempty"`
+	// Prompt size
+	PromptTokens   int             `json:"prompt_tokens,omitempty"`
+	PromptSections []promptSection `json:"prompt_sections,omitempty"`
+	// Effective strategy step config
+	Step *strategyStepConfig `json:"step,omitempty"`
 	// Model escalation
 	Model            string `json:"model,omitempty"`
+	ModelReason      string `json:"model_reason,omitempty"`
 	Escalated        bool   `json:"escalated,omitempty"`
 	EscalationReason string `json:"escalation_reason,omitempty"`
 	FromModel        string `json:"from,omitempty"`
 	ToModel          string `json:"to,omitempty"`
+	FromTier         *int   `json:"from_tier,omitempty"`
+	ToTier           *int   `json:"to_tier,omitempty"`
 	Cooldown         int    `json:"cooldown,omitempty"`
 	EscalationCount  int    `json:"escalation_count,omitempty"`
+	// Harness adapter
+	Adapter       string   `json:"adapter,omitempty"`
+	ToolCalls     int      `json:"tool_calls,omitempty"`
+	InputTokens   int      `json:"input_tokens,omitempty"`
+	OutputTokens  int      `json:"output_tokens,omitempty"`
+	Cost          float64  `json:"cost,omitempty"`
+	HarnessErrors []string `json:"harness_errors,omitempty"`
+	// Harness retries
+	Attempt    int    `json:"attempt,omitempty"`
+	Delay      string `json:"delay,omitempty"`
+	RetryRule  string `json:"retry_rule,omitempty"`
+	RetryAfter string `json:"retry_after,omitempty"`
+	ExitCode   int    `json:"exit_code,omitempty"`
+	// Harness session reuse
+	SessionID     string `json:"session_id,omitempty"`
+	SessionAction string `json:"session_action,omitempty"`
+	SessionReason string `json:"session_reason,omitempty"`
+	// Harness fallback
+	FallbackReason string `json:"fallback_reason,omitempty"`
+	// Guardrail hooks
+	Hook     string `json:"hook,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Message  string `json:"message,omitempty"`
+	// Phase 0d scope declaration
+	ScopeWillChange    []string `json:"scope_will_change,omitempty"`
+	ScopeWillNotChange []string `json:"scope_will_not_change,omitempty"`
+	ScopeUndeclared    []string `json:"scope_undeclared,omitempty"`
+	ScopeExcluded      []string `json:"scope_excluded,omitempty"`
+	// Large/binary file guardrail
+	LargeFiles []oversizedFile `json:"large_files,omitempty"`
+	// Parallel attempts
+	DiffLines  int    `json:"diff_lines,omitempty"`
+	Duration   string `json:"duration,omitempty"`
+	AttemptLog string `json:"attempt_log,omitempty"`
+	Selected   bool   `json:"selected,omitempty"`
+	Selection  string `json:"selection,omitempty"`
+	// Dependency guardrail
+	Manifests    []string           `json:"manifests,omitempty"`
+	Dependencies []dependencyChange `json:"dependencies,omitempty"`
 }
 
 func writeLogEntry(file *os.File, entry logEntry) {
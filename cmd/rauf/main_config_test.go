@@ -148,3 +148,36 @@ func TestLoadConfig_ReadError(t *testing.T) {
 		t.Error("expected read error")
 	}
 }
+
+func TestParseConfigStrategyGoto(t *testing.T) {
+	cfg := runtimeConfig{}
+	data := "strategy_max_iterations: 30\nstrategy:\n  - id: build # main loop\n    mode: build\n    until: \"stalled or no_unchecked_tasks\"\n  - mode: plan\n    if: stalled and has_unchecked_tasks\n    goto: build\n    repeat: 3\n"
+	if err := parseConfigBytes([]byte(data), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if cfg.StrategyMaxIterations != 30 || len(cfg.Strategy) != 2 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if cfg.Strategy[0].ID != "build" || cfg.Strategy[0].Until != "stalled or no_unchecked_tasks" {
+		t.Fatalf("unexpected first step: %+v", cfg.Strategy[0])
+	}
+	if cfg.Strategy[1].Goto != "build" || cfg.Strategy[1].Repeat != 3 || cfg.Strategy[1].If != "stalled and has_unchecked_tasks" {
+		t.Fatalf("unexpected second step: %+v", cfg.Strategy[1])
+	}
+}
+
+func TestParseConfigStrategyOverrides(t *testing.T) {
+	cfg := runtimeConfig{}
+	data := "strategy:\n  - mode: plan\n    harness: codex\n    model: strong # planning model\n    attempt_timeout: 10m\n  - mode: build\n    on_verify_fail: hard_reset\n    harness_args: \"\"\n"
+	if err := parseConfigBytes([]byte(data), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	plan := cfg.Strategy[0].Overrides
+	if plan.Harness != "codex" || plan.Model != "strong" || plan.AttemptTimeout == nil || *plan.AttemptTimeout != 10*time.Minute {
+		t.Fatalf("unexpected plan overrides: %+v", plan)
+	}
+	build := cfg.Strategy[1].Overrides
+	if build.OnVerifyFail != "hard_reset" || build.HarnessArgs == nil || *build.HarnessArgs != "" {
+		t.Fatalf("unexpected build overrides: %+v", build)
+	}
+}
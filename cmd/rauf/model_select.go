@@ -0,0 +1,35 @@
+package main
+
+import "strings"
+
+// Reasons recorded for the base model of an iteration.
+const (
+	modelReasonDefault    = "model_default"
+	modelReasonTask       = "task_model"
+	modelReasonComplexity = "task_complexity"
+	modelReasonStep       = "strategy_step"
+	modelReasonMode       = "model_by_mode"
+	modelReasonEscalation = "escalation"
+)
+
+// selectBaseModel picks the model an iteration starts from, most specific
+// first: the task's `Model:` line, its `Complexity:` mapped through
+// model_by_complexity, the strategy step's model, model_by_mode, and
+// finally model_default. Escalation climbs from the returned model.
+func selectBaseModel(cfg runtimeConfig, stepModel, mode string, task planTask) (string, string) {
+	if task.Model != "" {
+		return task.Model, modelReasonTask
+	}
+	if task.Complexity != "" {
+		if model := cfg.ModelByComplexity[task.Complexity]; model != "" {
+			return model, modelReasonComplexity + ":" + task.Complexity
+		}
+	}
+	if stepModel != "" {
+		return stepModel, modelReasonStep
+	}
+	if model := cfg.ModelByMode[strings.ToLower(mode)]; model != "" {
+		return model, modelReasonMode + ":" + mode
+	}
+	return cfg.ModelDefault, modelReasonDefault
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSelectBaseModel(t *testing.T) {
+	cfg := runtimeConfig{
+		ModelDefault:      "sonnet",
+		ModelByMode:       map[string]string{"plan": "opus"},
+		ModelByComplexity: map[string]string{"low": "haiku", "high": "opus"},
+	}
+	tests := []struct {
+		stepModel string
+		mode      string
+		task      planTask
+		model     string
+		reason    string
+	}{
+		{"", "build", planTask{}, "sonnet", "model_default"},
+		{"", "plan", planTask{}, "opus", "model_by_mode:plan"},
+		{"big", "plan", planTask{}, "big", "strategy_step"},
+		{"big", "build", planTask{Complexity: "low"}, "haiku", "task_complexity:low"},
+		{"", "build", planTask{Complexity: "medium"}, "sonnet", "model_default"},
+		{"big", "build", planTask{Model: "gpt-5", Complexity: "low"}, "gpt-5", "task_model"},
+	}
+	for _, tc := range tests {
+		model, reason := selectBaseModel(cfg, tc.stepModel, tc.mode, tc.task)
+		if model != tc.model || reason != tc.reason {
+			t.Errorf("selectBaseModel(%q, %q, %+v) = %q, %q; want %q, %q", tc.stepModel, tc.mode, tc.task, model, reason, tc.model, tc.reason)
+		}
+	}
+}
+
+func TestEscalationStartsFromTaskBaseTier(t *testing.T) {
+	cfg := runtimeConfig{
+		ModelDefault: "sonnet", // base model picked for a high-complexity task
+		Models:       []modelTier{{Name: "haiku"}, {Name: "sonnet"}, {Name: "opus"}},
+		ModelEscalation: escalationConfig{
+			Enabled:                true,
+			ConsecutiveVerifyFails: 2,
+			CooldownIters:          1,
+			MaxEscalations:         5,
+		},
+	}
+
+	// Left on haiku by an earlier task: the task's base tier wins.
+	state := raufState{CurrentModel: "haiku"}
+	if got := computeEffectiveModel(state, cfg); got != "sonnet" {
+		t.Fatalf("computeEffectiveModel() = %q, want the base model", got)
+	}
+
+	state.ConsecutiveVerifyFails = 2
+	state, event := updateModelEscalationState(state, cfg)
+	if event.Type != "escalated" || event.FromTier != 1 || event.ToTier != 2 || state.CurrentModel != "opus" {
+		t.Fatalf("expected climb from the base tier, got %+v", event)
+	}
+
+	state.ConsecutiveVerifyFails = 0
+	state.MinStrongIterationsRemaining = 0
+	state, event = updateModelEscalationState(state, cfg)
+	if event.Type != "de_escalated" || event.ToModel != "sonnet" || state.CurrentModel != "" {
+		t.Fatalf("expected drop back to the base model, got %+v state=%+v", event, state)
+	}
+	if shouldDeescalateModel(state, cfg) {
+		t.Fatalf("must not drop below the base tier")
+	}
+}
+
+func TestReadActiveTaskModelLines(t *testing.T) {
+	dir := t.TempDir()
+	plan := filepath.Join(dir, "PLAN.md")
+	content := "- [ ] T1: refactor parser\n  - Complexity: High\n  - Model: `claude-opus-4`\n  - Verify: go test ./...\n- [ ] T2: next\n  - Model: other\n"
+	if err := os.WriteFile(plan, []byte(content), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	task, ok, err := readActiveTask(plan)
+	if err != nil || !ok {
+		t.Fatalf("read failed: %v", err)
+	}
+	if task.Model != "claude-opus-4" || task.Complexity != "high" {
+		t.Fatalf("unexpected task model fields: %+v", task)
+	}
+}
+
+func TestParseConfigModelSelection(t *testing.T) {
+	cfg := runtimeConfig{}
+	data := "model_by_mode:\n  architect: opus\n  Plan: opus # planning\nmodel_by_complexity:\n  low: haiku\n  high: \"opus\"\n"
+	if err := parseConfigBytes([]byte(data), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if cfg.ModelByMode["architect"] != "opus" || cfg.ModelByMode["plan"] != "opus" {
+		t.Fatalf("unexpected model_by_mode: %v", cfg.ModelByMode)
+	}
+	if cfg.ModelByComplexity["low"] != "haiku" || cfg.ModelByComplexity["high"] != "opus" {
+		t.Fatalf("unexpected model_by_complexity: %v", cfg.ModelByComplexity)
+	}
+}
+
+func TestRunModeAppliesModeModel(t *testing.T) {
+	dir := t.TempDir()
+	chdirTemp(t, dir)
+	if err := os.WriteFile("PROMPT_plan.md", []byte("plan"), 0o644); err != nil {
+		t.Fatalf("write prompt failed: %v", err)
+	}
+
+	orig := runHarnessOnce
+	defer func() { runHarnessOnce = orig }()
+	var gotArgs, gotModel string
+	runHarnessOnce = func(ctx context.Context, prompt string, harness, harnessArgs string, logFile *os.File, runner runtimeExec) (string, error) {
+		gotArgs, gotModel = harnessArgs, runner.Model
+		return "done", nil
+	}
+
+	cfg := modeConfig{mode: "plan", promptFile: "PROMPT_plan.md", maxIterations: 1}
+	fileCfg := runtimeConfig{ModelDefault: "sonnet", ModelFlag: "--model", ModelByMode: map[string]string{"plan": "opus"}}
+	report := &RunReport{}
+	if _, err := runMode(context.Background(), cfg, fileCfg, runtimeExec{Quiet: true}, raufState{}, false, "", "IMPLEMENTATION_PLAN.md", "harness", "--model sonnet", true, "logs", false, 0, 0, 0, false, nil, 0, nil, nil, report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotArgs != "--model opus" || gotModel != "opus" {
+		t.Fatalf("expected the plan model, got args=%q model=%q", gotArgs, gotModel)
+	}
+	if len(report.Iterations) == 0 || report.Iterations[0].Model != "opus" || report.Iterations[0].ModelReason != "model_by_mode:plan" {
+		t.Fatalf("expected model and reason in the report: %+v", report.Iterations)
+	}
+}
@@ -0,0 +1,459 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Selection policies for parallel_select.
+const (
+	parallelSelectFirst        = "first"
+	parallelSelectSmallestDiff = "smallest_diff"
+)
+
+// errParallelVerifyFailed reports the selected attempt's failed verification.
+var errParallelVerifyFailed = errors.New("verification failed in the selected attempt")
+
+// parallelRequest describes one best-of-N build iteration.
+type parallelRequest struct {
+	Count          int
+	Policy         string
+	HeadBefore     string
+	Prompt         string
+	Harness        string
+	HarnessArgs    string
+	VerifyCmds     []string
+	AttemptTimeout time.Duration
+	Retry          retryConfig
+	LogFile        *os.File
+}
+
+// parallelAttempt is one attempt, run in its own git worktree at HeadBefore.
+type parallelAttempt struct {
+	Index        int
+	Model        string
+	Dir          string
+	LogPath      string
+	State        raufState
+	Result       harnessResult
+	Target       harnessTarget
+	Fallbacks    []harnessFallbackEvent
+	Err          error
+	VerifyStatus string
+	VerifyOutput string
+	HeadAfter    string
+	Patch        string
+	DiffLines    int
+	Duration     time.Duration
+	Cancelled    bool
+	worktree     bool
+}
+
+// parallelAttemptSummary is the run report entry for one attempt.
+type parallelAttemptSummary struct {
+	Attempt      int     `json:"attempt"`
+	Model        string  `json:"model,omitempty"`
+	VerifyStatus string  `json:"verify_status,omitempty"`
+	DiffLines    int     `json:"diff_lines"`
+	Duration     string  `json:"duration"`
+	Cost         float64 `json:"cost,omitempty"`
+	Error        string  `json:"error,omitempty"`
+	Cancelled    bool    `json:"cancelled,omitempty"`
+	Selected     bool    `json:"selected,omitempty"`
+	Log          string  `json:"log,omitempty"`
+}
+
+// parallelRun holds every attempt of an iteration and the one selected.
+// Winner is -1 when no attempt produced a usable result.
+type parallelRun struct {
+	Attempts  []parallelAttempt
+	Winner    int
+	Selection string
+}
+
+func normalizeParallelSelect(value string) string {
+	if strings.ToLower(strings.TrimSpace(value)) == parallelSelectSmallestDiff {
+		return parallelSelectSmallestDiff
+	}
+	return parallelSelectFirst
+}
+
+// parallelAttemptsBlocker returns why parallel attempts cannot run for this
+// iteration, or "" when they can.
+func parallelAttemptsBlocker(runner runtimeExec, gitAvailable bool, verifyCmds []string) string {
+	switch {
+	case !gitAvailable:
+		return "git is not available"
+	case len(verifyCmds) == 0:
+		return "the task has no Verify command"
+	case runner.isDocker() || runner.isDockerPersist():
+		return "docker runtimes are not supported"
+	case !isCleanWorkingTree():
+		return "the working tree has uncommitted changes"
+	}
+	return ""
+}
+
+// parallelAttemptModel cycles through parallel_models, falling back to the
+// iteration's model.
+func parallelAttemptModel(cfg runtimeConfig, i int, model string) string {
+	if len(cfg.ParallelModels) == 0 {
+		return model
+	}
+	return cfg.ParallelModels[i%len(cfg.ParallelModels)]
+}
+
+// runParallelAttempts runs req.Count attempts concurrently, each in a fresh
+// worktree, selects one and fast-forwards the main worktree to it. The other
+// worktrees are discarded.
+func runParallelAttempts(ctx context.Context, req parallelRequest, cfg runtimeConfig, state raufState, runner runtimeExec) (parallelRun, error) {
+	run := parallelRun{Attempts: make([]parallelAttempt, req.Count), Winner: -1}
+	root, err := os.MkdirTemp("", "rauf-attempts-")
+	if err != nil {
+		return run, fmt.Errorf("parallel attempts: %w", err)
+	}
+	defer removeParallelWorktrees(root, run.Attempts)
+
+	logBase := ""
+	if req.LogFile != nil {
+		logBase = strings.TrimSuffix(req.LogFile.Name(), ".jsonl")
+	}
+	// Worktrees are added one at a time; git locks the repository while adding.
+	for i := range run.Attempts {
+		a := &run.Attempts[i]
+		a.Index = i + 1
+		a.Model = parallelAttemptModel(cfg, i, runner.Model)
+		a.Dir = filepath.Join(root, "attempt-"+strconv.Itoa(a.Index))
+		a.State = state
+		if logBase != "" {
+			a.LogPath = fmt.Sprintf("%s-attempt-%d.jsonl", logBase, a.Index)
+		}
+		if _, err := gitExec("worktree", "add", "--detach", a.Dir, req.HeadBefore); err != nil {
+			a.Err = fmt.Errorf("git worktree add failed: %w", err)
+			continue
+		}
+		a.worktree = true
+	}
+
+	runCtx, cancelAll := context.WithCancel(ctx)
+	defer cancelAll()
+	done := make(chan int, len(run.Attempts))
+	for i := range run.Attempts {
+		if run.Attempts[i].Err != nil {
+			done <- i
+			continue
+		}
+		go func(a *parallelAttempt, i int) {
+			runParallelAttempt(runCtx, a, req, cfg, runner)
+			done <- i
+		}(&run.Attempts[i], i)
+	}
+	for range run.Attempts {
+		i := <-done
+		// "first" takes the first attempt to pass and stops the others.
+		if run.Winner < 0 && req.Policy == parallelSelectFirst && run.Attempts[i].VerifyStatus == "pass" && run.Attempts[i].Err == nil {
+			run.Winner, run.Selection = i, "first_pass"
+			cancelAll()
+		}
+	}
+	if run.Winner < 0 {
+		run.Winner, run.Selection = selectParallelAttempt(run.Attempts, req.Policy)
+	}
+	if run.Winner < 0 || ctx.Err() != nil {
+		return run, nil
+	}
+	if err := applyParallelAttempt(run.Attempts[run.Winner], req.HeadBefore); err != nil {
+		return run, err
+	}
+	return run, nil
+}
+
+// runParallelAttempt runs the harness chain and the task's Verify inside the
+// attempt's worktree, then records the attempt's changes.
+func runParallelAttempt(ctx context.Context, a *parallelAttempt, req parallelRequest, cfg runtimeConfig, runner runtimeExec) {
+	start := time.Now()
+	defer func() { a.Duration = time.Since(start) }()
+
+	var logFile *os.File
+	if a.LogPath != "" {
+		f, err := os.Create(a.LogPath)
+		if err != nil {
+			a.Err = err
+			return
+		}
+		defer f.Close()
+		logFile = f
+	}
+
+	args := req.HarnessArgs
+	if a.Model != "" && a.Model != runner.Model && !strings.Contains(args, "{model}") {
+		args = applyModelChoice(args, cfg.ModelFlag, a.Model, true)
+	}
+	attemptRunner := runner
+	attemptRunner.WorkDir = a.Dir
+	attemptRunner.Quiet = true
+	attemptRunner.Attempt = a.Index
+	attemptRunner.Model = a.Model
+	attemptRunner.SessionID = ""
+
+	chain := harnessChain(cfg, req.Harness, args)
+	a.Result, a.Target, a.Fallbacks, a.Err = runHarnessChain(ctx, req.AttemptTimeout, req.Prompt, chain, cfg, &a.State, logFile, req.Retry, attemptRunner, runner.Mode, runner.Iteration)
+	if a.Err == nil {
+		output, err := runVerificationTo(ctx, attemptRunner, req.VerifyCmds, io.Discard, io.Discard, logFile)
+		a.VerifyOutput = output
+		if err != nil {
+			a.VerifyStatus = "fail"
+		} else {
+			a.VerifyStatus = "pass"
+		}
+	}
+	if ctx.Err() != nil {
+		a.Cancelled = true
+		a.VerifyStatus = ""
+		return
+	}
+	var err error
+	a.HeadAfter, a.Patch, a.DiffLines, err = captureAttemptChanges(a.Dir, req.HeadBefore)
+	if err != nil && a.Err == nil {
+		a.Err = err
+	}
+}
+
+// selectParallelAttempt picks a passing attempt (the lowest index, or the
+// smallest diff for smallest_diff). Without one, the first attempt whose
+// harness run succeeded is kept so the failure is handled like a single run.
+func selectParallelAttempt(attempts []parallelAttempt, policy string) (int, string) {
+	best := -1
+	for i, a := range attempts {
+		if a.VerifyStatus != "pass" || a.Err != nil {
+			continue
+		}
+		if best < 0 || (policy == parallelSelectSmallestDiff && a.DiffLines < attempts[best].DiffLines) {
+			best = i
+		}
+	}
+	if best >= 0 {
+		if policy == parallelSelectSmallestDiff {
+			return best, "smallest_diff"
+		}
+		return best, "first_pass"
+	}
+	for i, a := range attempts {
+		if a.Err == nil && !a.Cancelled {
+			return i, "no_pass"
+		}
+	}
+	return -1, "all_failed"
+}
+
+// captureAttemptChanges stages everything in the worktree and returns its
+// HEAD, the uncommitted changes as a binary patch and the number of changed
+// lines relative to headBefore.
+func captureAttemptChanges(dir, headBefore string) (string, string, int, error) {
+	head, err := gitStdout(gitDirArgs(dir, "rev-parse", "HEAD")...)
+	if err != nil {
+		return "", "", 0, err
+	}
+	head = strings.TrimSpace(head)
+	if _, err := gitExec(gitDirArgs(dir, "add", "-A")...); err != nil {
+		return head, "", 0, err
+	}
+	patch, err := gitStdout(gitDirArgs(dir, "diff", "--cached", "--binary", "HEAD")...)
+	if err != nil {
+		return head, "", 0, err
+	}
+	numstat, err := gitStdout(gitDirArgs(dir, "diff", "--cached", "--numstat", headBefore)...)
+	if err != nil {
+		return head, patch, 0, err
+	}
+	return head, patch, countNumstatLines(numstat), nil
+}
+
+// countNumstatLines sums added and deleted lines; binary files count as one.
+func countNumstatLines(numstat string) int {
+	total := 0
+	for _, line := range strings.Split(numstat, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		added, errA := strconv.Atoi(fields[0])
+		deleted, errD := strconv.Atoi(fields[1])
+		if errA != nil || errD != nil {
+			total++
+			continue
+		}
+		total += added + deleted
+	}
+	return total
+}
+
+// applyParallelAttempt fast-forwards the main worktree to the attempt's
+// commits and carries over its uncommitted changes.
+func applyParallelAttempt(a parallelAttempt, headBefore string) error {
+	if a.HeadAfter != "" && a.HeadAfter != headBefore {
+		if err := gitQuiet("merge", "--ff-only", "--quiet", a.HeadAfter); err != nil {
+			return fmt.Errorf("fast-forward to attempt %d failed: %w", a.Index, err)
+		}
+	}
+	if strings.TrimSpace(a.Patch) == "" {
+		return nil
+	}
+	f, err := os.CreateTemp("", "rauf-attempt-*.patch")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(a.Patch); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := gitQuiet("apply", f.Name()); err != nil {
+		return fmt.Errorf("applying changes from attempt %d failed: %w", a.Index, err)
+	}
+	return nil
+}
+
+func removeParallelWorktrees(root string, attempts []parallelAttempt) {
+	for _, a := range attempts {
+		if a.worktree {
+			_ = gitQuiet("worktree", "remove", "--force", a.Dir)
+		}
+	}
+	_ = os.RemoveAll(root)
+	_ = gitQuiet("worktree", "prune")
+}
+
+// gitDirArgs prefixes git args with -C dir when dir is set.
+func gitDirArgs(dir string, args ...string) []string {
+	if dir == "" || dir == "." {
+		return args
+	}
+	return append([]string{"-C", dir}, args...)
+}
+
+// primary is the attempt whose harness result stands for the iteration: the
+// winner, or the first attempt when none was usable.
+func (r parallelRun) primary() int {
+	if r.Winner >= 0 {
+		return r.Winner
+	}
+	return 0
+}
+
+// result returns the primary attempt's harness result in runHarnessChain's shape.
+func (r parallelRun) result() (harnessResult, harnessTarget, []harnessFallbackEvent, error) {
+	a := r.Attempts[r.primary()]
+	err := a.Err
+	if r.Winner < 0 && err == nil {
+		err = errors.New("no parallel attempt completed")
+	}
+	return a.Result, a.Target, a.Fallbacks, err
+}
+
+// verification returns the selected attempt's verify output.
+func (r parallelRun) verification() (string, error) {
+	a := r.Attempts[r.primary()]
+	if a.VerifyStatus != "pass" {
+		return a.VerifyOutput, errParallelVerifyFailed
+	}
+	return a.VerifyOutput, nil
+}
+
+// attemptUsage returns the tokens and estimated cost of one attempt.
+func attemptUsage(cfg runtimeConfig, a parallelAttempt) (tokenUsage, float64) {
+	usage := a.Result.Usage
+	if cfg.TokenUsage.enabled() {
+		if extracted, ok := extractTokenUsage(cfg.TokenUsage, a.Result.RawOutput); ok {
+			usage = extracted
+		}
+	}
+	return usage, estimateCost(cfg.Pricing, firstNonEmpty(a.Target.Model, a.Model), usage)
+}
+
+func (r parallelRun) summaries(cfg runtimeConfig) []parallelAttemptSummary {
+	out := make([]parallelAttemptSummary, 0, len(r.Attempts))
+	for i, a := range r.Attempts {
+		_, cost := attemptUsage(cfg, a)
+		s := parallelAttemptSummary{
+			Attempt:      a.Index,
+			Model:        a.Model,
+			VerifyStatus: a.VerifyStatus,
+			DiffLines:    a.DiffLines,
+			Duration:     a.Duration.Round(time.Millisecond).String(),
+			Cost:         cost,
+			Cancelled:    a.Cancelled,
+			Selected:     i == r.Winner,
+			Log:          a.LogPath,
+		}
+		if a.Err != nil && !a.Cancelled {
+			s.Error = a.Err.Error()
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// logParallelRun writes one parallel_attempt entry per attempt and a
+// parallel_select entry for the selection.
+func logParallelRun(logFile *os.File, mode string, iteration int, r parallelRun) {
+	for i, a := range r.Attempts {
+		entry := logEntry{
+			Type:         "parallel_attempt",
+			Mode:         mode,
+			Iteration:    iteration,
+			Attempt:      a.Index,
+			Model:        a.Model,
+			VerifyStatus: a.VerifyStatus,
+			HeadAfter:    a.HeadAfter,
+			DiffLines:    a.DiffLines,
+			Duration:     a.Duration.Round(time.Millisecond).String(),
+			AttemptLog:   a.LogPath,
+			Selected:     i == r.Winner,
+		}
+		switch {
+		case a.Cancelled:
+			entry.ExitReason = "cancelled"
+		case a.Err != nil:
+			entry.ExitReason = "harness_failed"
+			entry.Message = a.Err.Error()
+		}
+		writeLogEntry(logFile, entry)
+	}
+	entry := logEntry{Type: "parallel_select", Mode: mode, Iteration: iteration, Selection: r.Selection}
+	if r.Winner >= 0 {
+		entry.Attempt = r.Attempts[r.Winner].Index
+		entry.HeadAfter = r.Attempts[r.Winner].HeadAfter
+	}
+	writeLogEntry(logFile, entry)
+}
+
+func printParallelRun(r parallelRun) {
+	for i, a := range r.Attempts {
+		status := a.VerifyStatus
+		switch {
+		case a.Cancelled:
+			status = "cancelled"
+		case a.Err != nil:
+			status = "failed: " + a.Err.Error()
+		}
+		marker := " "
+		if i == r.Winner {
+			marker = "*"
+		}
+		fmt.Printf("%s Attempt %d (%s): %s, %d lines changed, %s\n", marker, a.Index, firstNonEmpty(a.Model, "default model"), status, a.DiffLines, a.Duration.Round(time.Second))
+	}
+	if r.Winner >= 0 {
+		fmt.Printf("Selected attempt %d (%s)\n", r.Attempts[r.Winner].Index, r.Selection)
+	}
+}
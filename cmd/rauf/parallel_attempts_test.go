@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSelectParallelAttempt(t *testing.T) {
+	attempts := []parallelAttempt{
+		{Index: 1, VerifyStatus: "fail"},
+		{Index: 2, VerifyStatus: "pass", DiffLines: 40},
+		{Index: 3, VerifyStatus: "pass", DiffLines: 12},
+		{Index: 4, VerifyStatus: "pass", DiffLines: 5, Err: errors.New("git failed")},
+	}
+	if got, reason := selectParallelAttempt(attempts, parallelSelectFirst); got != 1 || reason != "first_pass" {
+		t.Fatalf("first: got %d %q", got, reason)
+	}
+	if got, reason := selectParallelAttempt(attempts, parallelSelectSmallestDiff); got != 2 || reason != "smallest_diff" {
+		t.Fatalf("smallest_diff: got %d %q", got, reason)
+	}
+
+	failing := []parallelAttempt{
+		{Index: 1, Err: errors.New("harness failed")},
+		{Index: 2, Cancelled: true},
+		{Index: 3, VerifyStatus: "fail"},
+	}
+	if got, reason := selectParallelAttempt(failing, parallelSelectFirst); got != 2 || reason != "no_pass" {
+		t.Fatalf("no pass: got %d %q", got, reason)
+	}
+	if got, reason := selectParallelAttempt(failing[:2], parallelSelectFirst); got != -1 || reason != "all_failed" {
+		t.Fatalf("all failed: got %d %q", got, reason)
+	}
+}
+
+func TestCountNumstatLines(t *testing.T) {
+	numstat := "3\t1\tmain.go\n-\t-\tlogo.png\n10\t0\tREADME.md\n"
+	if got := countNumstatLines(numstat); got != 15 {
+		t.Fatalf("countNumstatLines() = %d, want 15", got)
+	}
+}
+
+func TestParseConfigParallelAttempts(t *testing.T) {
+	cfg := runtimeConfig{}
+	data := "parallel_attempts: 3\nparallel_models:\n  - claude-sonnet-4\n  - \"claude-opus-4\" # strong\nparallel_select: smallest_diff\n"
+	if err := parseConfigBytes([]byte(data), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if cfg.ParallelAttempts != 3 || normalizeParallelSelect(cfg.ParallelSelect) != parallelSelectSmallestDiff {
+		t.Fatalf("unexpected parallel config: %+v", cfg)
+	}
+	if strings.Join(cfg.ParallelModels, ",") != "claude-sonnet-4,claude-opus-4" {
+		t.Fatalf("unexpected parallel models: %q", cfg.ParallelModels)
+	}
+	if normalizeParallelSelect("") != parallelSelectFirst {
+		t.Fatalf("expected first as the default policy")
+	}
+}
+
+// setupParallelRepo creates a repo with a plan whose task verifies greeting.txt.
+func setupParallelRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+	chdirTemp(t, dir)
+	files := map[string]string{
+		"PLAN.md":         "# Plan\n- [ ] T1: add greeting\n  - Verify: grep -q world greeting.txt\n",
+		"PROMPT_build.md": "build",
+		".gitignore":      "logs/\n.rauf/\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+	}
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-m", "setup")
+	return dir
+}
+
+func TestRunParallelAttemptsCarriesUncommittedChanges(t *testing.T) {
+	dir := setupParallelRepo(t)
+	head := runGit(t, dir, "rev-parse", "HEAD")
+
+	orig := runHarnessOnce
+	defer func() { runHarnessOnce = orig }()
+	runHarnessOnce = func(ctx context.Context, prompt string, harness, harnessArgs string, logFile *os.File, runner runtimeExec) (string, error) {
+		content := "hello\n"
+		if runner.Attempt == 2 {
+			content = "hello world\n"
+		}
+		return "", os.WriteFile(filepath.Join(runner.WorkDir, "greeting.txt"), []byte(content), 0o644)
+	}
+
+	req := parallelRequest{
+		Count:      3,
+		Policy:     parallelSelectFirst,
+		HeadBefore: head,
+		Harness:    "harness",
+		VerifyCmds: []string{"grep -q world greeting.txt"},
+	}
+	run, err := runParallelAttempts(context.Background(), req, runtimeConfig{}, raufState{}, runtimeExec{Quiet: true, Mode: "build", Iteration: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if run.Winner != 1 || run.Selection != "first_pass" {
+		t.Fatalf("expected attempt 2 to win, got %d (%s): %+v", run.Winner, run.Selection, run.Attempts)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "greeting.txt"))
+	if err != nil || string(data) != "hello world\n" {
+		t.Fatalf("expected the winning change in the main worktree, got %q err=%v", data, err)
+	}
+	if got := runGit(t, dir, "rev-parse", "HEAD"); got != head {
+		t.Fatalf("HEAD moved without commits: %s", got)
+	}
+	if worktrees := runGit(t, dir, "worktree", "list"); strings.Count(worktrees, "\n") != 0 {
+		t.Fatalf("expected attempt worktrees to be removed:\n%s", worktrees)
+	}
+}
+
+func TestRunModeParallelAttempts(t *testing.T) {
+	dir := setupParallelRepo(t)
+
+	orig := runHarnessOnce
+	defer func() { runHarnessOnce = orig }()
+	var mu sync.Mutex
+	args := map[int]string{}
+	runHarnessOnce = func(ctx context.Context, prompt string, harness, harnessArgs string, logFile *os.File, runner runtimeExec) (string, error) {
+		mu.Lock()
+		args[runner.Attempt] = harnessArgs
+		mu.Unlock()
+		write := func(name, content string) error {
+			return os.WriteFile(filepath.Join(runner.WorkDir, name), []byte(content), 0o644)
+		}
+		commit := func(msg string) error {
+			if _, err := gitExec("-C", runner.WorkDir, "add", "-A"); err != nil {
+				return err
+			}
+			_, err := gitExec("-C", runner.WorkDir, "-c", "user.name=T", "-c", "user.email=t@example.com", "commit", "-m", msg)
+			return err
+		}
+		switch runner.Attempt {
+		case 1: // fails verify
+			if err := write("greeting.txt", "hello\n"); err != nil {
+				return "", err
+			}
+			return "", commit("T1: add greeting")
+		case 2: // passes with the smallest diff
+			if err := write("greeting.txt", "hello world\n"); err != nil {
+				return "", err
+			}
+			if err := write("PLAN.md", "# Plan\n- [x] T1: add greeting\n  - Verify: grep -q world greeting.txt\n"); err != nil {
+				return "", err
+			}
+			return "RAUF_COMPLETE\n", commit("T1: add greeting")
+		default: // passes with a larger, uncommitted diff
+			return "", write("greeting.txt", "hello world\nand\nmore\nlines\n")
+		}
+	}
+
+	cfg := modeConfig{mode: "build", promptFile: "PROMPT_build.md", maxIterations: 2}
+	fileCfg := runtimeConfig{
+		NoProgressIters:  5,
+		ModelFlag:        "--model",
+		ParallelAttempts: 3,
+		ParallelModels:   []string{"small", "big"},
+		ParallelSelect:   parallelSelectSmallestDiff,
+	}
+	report := &RunReport{}
+	res, err := runMode(context.Background(), cfg, fileCfg, runtimeExec{Quiet: true}, raufState{}, true, "", "PLAN.md", "harness", "--model base", true, "logs", false, 0, 0, 0, false, nil, 0, nil, nil, report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.ExitReason != "completion_contract_satisfied" {
+		t.Fatalf("unexpected exit reason %q (iterations %+v)", res.ExitReason, report.Iterations)
+	}
+	if args[1] != "--model small" || args[2] != "--model big" || args[3] != "--model small" {
+		t.Fatalf("expected parallel_models to cycle, got %v", args)
+	}
+	if subjects := runGit(t, dir, "log", "--format=%s", "-2"); subjects != "T1: add greeting\nsetup" {
+		t.Fatalf("expected the winning commit to be fast-forwarded, got %q", subjects)
+	}
+	if status := runGit(t, dir, "status", "--porcelain"); status != "" {
+		t.Fatalf("expected a clean tree, got %q", status)
+	}
+
+	summaries := report.Iterations[0].ParallelAttempts
+	if len(summaries) != 3 || !summaries[1].Selected || summaries[0].VerifyStatus != "fail" || summaries[2].VerifyStatus != "pass" {
+		t.Fatalf("unexpected attempt summaries: %+v", summaries)
+	}
+	if summaries[1].DiffLines >= summaries[2].DiffLines || summaries[1].Log == "" {
+		t.Fatalf("unexpected diff sizes or log paths: %+v", summaries)
+	}
+	if _, err := os.Stat(summaries[0].Log); err != nil {
+		t.Fatalf("expected a per-attempt log: %v", err)
+	}
+	logs, _ := filepath.Glob(filepath.Join(dir, "logs", "build-*.jsonl"))
+	found := false
+	for _, path := range logs {
+		data, _ := os.ReadFile(path)
+		if strings.Contains(string(data), `"type":"parallel_select"`) && strings.Contains(string(data), `"selection":"smallest_diff"`) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a parallel_select log entry in %v", logs)
+	}
+}
+
+func TestCaptureAttemptChangesIgnoresGitWarnings(t *testing.T) {
+	dir := t.TempDir()
+	head := initGitRepo(t, dir)
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	// Tracing makes every git command write to stderr.
+	t.Setenv("GIT_TRACE", "1")
+	headAfter, patch, lines, err := captureAttemptChanges(dir, head)
+	if err != nil {
+		t.Fatalf("capture failed: %v", err)
+	}
+	if headAfter != head {
+		t.Fatalf("expected HEAD %s, got %q", head, headAfter)
+	}
+	if !strings.HasPrefix(patch, "diff --git ") || strings.Contains(patch, "trace:") {
+		t.Fatalf("expected a clean patch, got %q", patch)
+	}
+	if lines != 2 {
+		t.Fatalf("expected 2 changed lines, got %d", lines)
+	}
+}
@@ -14,6 +14,8 @@ type planTask struct {
 	SpecRefs          []string
 	TaskBlock         []string
 	FilesMentioned    []string
+	Model             string // from a `- Model:` line
+	Complexity        string // from a `- Complexity:` line, lowercased
 }
 
 type planLintResult struct {
@@ -31,6 +33,8 @@ func readActiveTask(planPath string) (planTask, bool, error) {
 	taskLine := regexp.MustCompile(`^\s*[-*]\s+\[\s\]\s+(.+)$`)
 	verifyLine := regexp.MustCompile(`^\s*[-*]\s+Verify:\s*(.*)$`)
 	specLine := regexp.MustCompile(`^\s*[-*]\s+Spec:\s*(.+)$`)
+	modelLine := regexp.MustCompile(`^\s*[-*]\s+Model:\s*(.+)$`)
+	complexityLine := regexp.MustCompile(`^\s*[-*]\s+Complexity:\s*(.+)$`)
 
 	var task planTask
 	found := false
@@ -113,6 +117,12 @@ func readActiveTask(planPath string) (planTask, bool, error) {
 				}
 			}
 		}
+		if match := modelLine.FindStringSubmatch(line); match != nil {
+			task.Model = strings.TrimSpace(strings.Trim(strings.TrimSpace(match[1]), "`"))
+		}
+		if match := complexityLine.FindStringSubmatch(line); match != nil {
+			task.Complexity = strings.ToLower(strings.TrimSpace(strings.Trim(strings.TrimSpace(match[1]), "`")))
+		}
 	}
 
 	if err := scanner.Err(); err != nil {
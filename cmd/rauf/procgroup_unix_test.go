@@ -0,0 +1,57 @@
+//go:build !windows
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCommandCancelKillsProcessGroup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd, err := runtimeExec{}.command(ctx, "sh", "-c", "sleep 30 & echo started; wait")
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	// The backgrounded sleep inherits stdout, so Wait blocks until it exits.
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	start := time.Now()
+	cancel()
+	_ = cmd.Wait()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("grandchild survived cancel; Wait took %s", elapsed)
+	}
+}
+
+func TestWatchInterruptsTwoStage(t *testing.T) {
+	ctx, interrupts := watchInterrupts(context.Background())
+	defer interrupts.stop()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("signal failed: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !interruptRequested(ctx) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !interruptRequested(ctx) || ctx.Err() != nil {
+		t.Fatalf("first signal should request a stop without cancelling (err=%v)", ctx.Err())
+	}
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("signal failed: %v", err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatalf("second signal should cancel the context")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package main
+
+import "os/exec"
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateProcessGroup kills the process; Windows has no SIGTERM equivalent.
+func terminateProcessGroup(cmd *exec.Cmd, force bool) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// bytesPerToken is the rough bytes-per-token ratio used to estimate prompt
+// size without a model-specific tokenizer.
+const bytesPerToken = 4
+
+// promptSection records the size of one trimmable prompt section.
+type promptSection struct {
+	Name      string `json:"name"`
+	Bytes     int    `json:"bytes"`
+	Truncated int    `json:"truncated,omitempty"`
+}
+
+// promptSectionPriority lists trimmable sections from highest to lowest
+// priority. Budget trimming walks it backwards.
+var promptSectionPriority = []string{"backpressure", "task", "verify_output", "context_pack", "repo_map", "spec_index"}
+
+func estimateTokens(s string) int {
+	return (len(s) + bytesPerToken - 1) / bytesPerToken
+}
+
+func promptSectionField(data *promptData, name string) *string {
+	switch name {
+	case "backpressure":
+		return &data.Backpressure
+	case "task":
+		return &data.PlanSummary
+	case "verify_output":
+		return &data.PriorVerification
+	case "context_pack":
+		return &data.ContextPack
+	case "repo_map":
+		return &data.RepoMap
+	case "spec_index":
+		return &data.SpecIndex
+	}
+	return nil
+}
+
+// trimPromptSection cuts at least excess bytes from value and appends a
+// visible truncation note. Verify output keeps its tail, where errors are.
+func trimPromptSection(value string, excess int, keepTail bool) (string, int) {
+	keep := len(value) - excess - len("\n[truncated 0000000 bytes]\n")
+	var kept string
+	if keepTail {
+		kept = truncateTail(value, keep)
+	} else {
+		kept = truncateHead(value, keep)
+	}
+	removed := len(value) - len(kept)
+	note := fmt.Sprintf("[truncated %d bytes]", removed)
+	if kept == "" {
+		return note, removed
+	}
+	if keepTail {
+		return note + "\n" + kept, removed
+	}
+	return kept + "\n" + note, removed
+}
+
+// applyPromptBudget trims sections, lowest priority first, until the rendered
+// prompt fits budgetTokens. Sections the template does not use are skipped.
+func applyPromptBudget(data *promptData, budgetTokens int, render func() (string, error)) (string, map[string]int, error) {
+	out, err := render()
+	truncated := map[string]int{}
+	if err != nil || budgetTokens <= 0 {
+		return out, truncated, err
+	}
+	for i := len(promptSectionPriority) - 1; i >= 0; i-- {
+		over := estimateTokens(out) - budgetTokens
+		if over <= 0 {
+			break
+		}
+		name := promptSectionPriority[i]
+		field := promptSectionField(data, name)
+		if *field == "" || !strings.Contains(out, *field) {
+			continue
+		}
+		var removed int
+		*field, removed = trimPromptSection(*field, over*bytesPerToken, name == "verify_output")
+		if removed == 0 {
+			continue
+		}
+		truncated[name] += removed
+		if out, err = render(); err != nil {
+			return out, truncated, err
+		}
+	}
+	return out, truncated, nil
+}
+
+func promptSectionSizes(data promptData, truncated map[string]int) []promptSection {
+	sections := make([]promptSection, 0, len(promptSectionPriority))
+	for _, name := range promptSectionPriority {
+		sections = append(sections, promptSection{
+			Name:      name,
+			Bytes:     len(*promptSectionField(&data, name)),
+			Truncated: truncated[name],
+		})
+	}
+	return sections
+}
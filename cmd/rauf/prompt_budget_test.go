@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTrimPromptSection(t *testing.T) {
+	value := strings.Repeat("a", 100) + strings.Repeat("z", 100)
+	head, removed := trimPromptSection(value, 50, false)
+	if !strings.HasPrefix(head, "aaa") || !strings.HasSuffix(head, fmt.Sprintf("[truncated %d bytes]", removed)) || removed < 50 {
+		t.Fatalf("unexpected head trim (removed %d): %q", removed, head)
+	}
+	tail, removed := trimPromptSection(value, 50, true)
+	if !strings.HasPrefix(tail, "[truncated ") || !strings.HasSuffix(tail, "zzz") || removed < 50 {
+		t.Fatalf("unexpected tail trim (removed %d): %q", removed, tail)
+	}
+	all, removed := trimPromptSection("short", 1000, false)
+	if all != "[truncated 5 bytes]" || removed != 5 {
+		t.Fatalf("unexpected full trim: %q %d", all, removed)
+	}
+}
+
+func TestBuildPromptContentBudget(t *testing.T) {
+	dir := t.TempDir()
+	promptFile := filepath.Join(dir, "PROMPT.md")
+	tmpl := "Task: {{.ActiveTask}}\n{{.PlanSummary}}\n{{.PriorVerification}}\n{{.ContextPack}}\n{{.RepoMap}}\n"
+	if err := os.WriteFile(promptFile, []byte(tmpl), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	data := promptData{
+		ActiveTask:        "T1: keep me",
+		PlanSummary:       "plan summary",
+		PriorVerification: strings.Repeat("v", 400) + "FAIL: TestX",
+		ContextPack:       strings.Repeat("c", 2000),
+		RepoMap:           strings.Repeat("r", 4000),
+		SpecIndex:         strings.Repeat("s", 8000), // not referenced by the template
+		Backpressure:      "## Backpressure\nfix the build",
+	}
+
+	full, _, sections, err := buildPromptContent(promptFile, data, 0)
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if !strings.HasPrefix(full, "## Backpressure\nfix the build\n\nTask: T1: keep me") {
+		t.Fatalf("expected backpressure to be prepended, got %q", full[:60])
+	}
+	if sections[4].Name != "repo_map" || sections[4].Bytes != 4000 || sections[4].Truncated != 0 {
+		t.Fatalf("unexpected untrimmed sections: %+v", sections)
+	}
+
+	budget := 500 // ~2000 bytes
+	trimmed, hash, sections, err := buildPromptContent(promptFile, data, budget)
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if estimateTokens(trimmed) > budget {
+		t.Fatalf("prompt still over budget: %d tokens", estimateTokens(trimmed))
+	}
+	got := map[string]promptSection{}
+	for _, s := range sections {
+		got[s.Name] = s
+	}
+	if got["spec_index"].Truncated != 0 || got["repo_map"].Truncated != 4000 || got["context_pack"].Truncated == 0 {
+		t.Fatalf("expected repo map then context pack to be trimmed: %+v", sections)
+	}
+	if got["verify_output"].Truncated != 0 || got["backpressure"].Truncated != 0 {
+		t.Fatalf("higher-priority sections must be kept: %+v", sections)
+	}
+	if !strings.Contains(trimmed, "[truncated 4000 bytes]") || !strings.Contains(trimmed, "FAIL: TestX") || !strings.Contains(trimmed, "fix the build") {
+		t.Fatalf("unexpected trimmed prompt: %q", trimmed)
+	}
+	if hash == "" {
+		t.Fatalf("expected hash")
+	}
+}
+
+func TestParseConfigPromptBudget(t *testing.T) {
+	cfg := runtimeConfig{}
+	if err := parseConfigBytes([]byte("prompt_budget_tokens: 120000\n"), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if cfg.PromptBudgetTokens != 120000 {
+		t.Fatalf("unexpected budget: %d", cfg.PromptBudgetTokens)
+	}
+}
@@ -31,6 +31,8 @@ type promptData struct {
 	PriorVerification       string
 	PriorVerificationCmd    string
 	PriorVerificationStatus string
+	// Backpressure is prepended to the rendered template.
+	Backpressure string
 }
 
 const (
@@ -43,10 +45,13 @@ const (
 	maxContextBytes    = 8 * 1024
 )
 
-func buildPromptContent(promptFile string, data promptData) (string, string, error) {
+// buildPromptContent renders the prompt template. With budgetTokens > 0,
+// lower-priority sections are trimmed until the prompt fits. The hash covers
+// the rendered template without backpressure.
+func buildPromptContent(promptFile string, data promptData, budgetTokens int) (string, string, []promptSection, error) {
 	content, err := os.ReadFile(promptFile)
 	if err != nil {
-		return "", "", err
+		return "", "", nil, err
 	}
 
 	// Escape template delimiters in user-controlled fields to prevent injection
@@ -58,16 +63,27 @@ func buildPromptContent(promptFile string, data promptData) (string, string, err
 
 	tmpl, err := template.New(filepath.Base(promptFile)).Option("missingkey=zero").Parse(string(content))
 	if err != nil {
-		return "", "", err
+		return "", "", nil, err
 	}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", "", err
+	rendered := ""
+	render := func() (string, error) {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", err
+		}
+		rendered = buf.String()
+		if data.Backpressure != "" {
+			return data.Backpressure + "\n\n" + rendered, nil
+		}
+		return rendered, nil
+	}
+	full, truncated, err := applyPromptBudget(&data, budgetTokens, render)
+	if err != nil {
+		return "", "", nil, err
 	}
-	rendered := buf.String()
 	hash := sha256.Sum256([]byte(rendered))
-	return rendered, fmt.Sprintf("%x", hash), nil
+	return full, fmt.Sprintf("%x", hash), promptSectionSizes(data, truncated), nil
 }
 
 // escapeTemplateDelimiters escapes Go template delimiters to prevent template injection
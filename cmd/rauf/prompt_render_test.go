@@ -17,7 +17,7 @@ func TestBuildPromptContent(t *testing.T) {
 		ActiveTask: "My Task {{injection}}",
 	}
 
-	rendered, hash, err := buildPromptContent(promptFile, data)
+	rendered, hash, _, err := buildPromptContent(promptFile, data, 0)
 	if err != nil {
 		t.Fatalf("buildPromptContent failed: %v", err)
 	}
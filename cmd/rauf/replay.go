@@ -0,0 +1,488 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// replayHarnessName is the built-in harness that plays back scripted responses
+// from a fixture file instead of running a model. The fixture path is the
+// first harness argument.
+const replayHarnessName = "rauf-replay"
+
+// replayResponse is one scripted harness run. Mode and Iteration are optional
+// selectors; responses without an iteration are consumed in file order.
+type replayResponse struct {
+	Mode         string       `json:"mode"`
+	Iteration    int          `json:"iteration"`
+	ExpectPrompt string       `json:"expect_prompt"`
+	Edits        []replayEdit `json:"edits"`
+	Commit       string       `json:"commit"`
+	Output       string       `json:"output"`
+	Hypothesis   string       `json:"hypothesis"`
+	Different    string       `json:"different_this_time"`
+	Question     string       `json:"question"`
+	Complete     bool         `json:"complete"`
+	ExitCode     int          `json:"exit_code"`
+}
+
+type replayEdit struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+	Append  bool   `json:"append"`
+	Delete  bool   `json:"delete"`
+}
+
+// replayExitError mimics a harness exiting with a non-zero status.
+type replayExitError struct {
+	code int
+}
+
+func (e replayExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+func (e replayExitError) ExitCode() int {
+	return e.code
+}
+
+// replayPlayer tracks which responses of a fixture have been used.
+type replayPlayer struct {
+	responses []replayResponse
+	used      []bool
+}
+
+var (
+	replayMu      sync.Mutex
+	replayPlayers = map[string]*replayPlayer{}
+)
+
+func isReplayHarness(harness string) bool {
+	return harness == replayHarnessName
+}
+
+// resetReplayPlayers forgets fixture progress so the next run starts over.
+func resetReplayPlayers() {
+	replayMu.Lock()
+	defer replayMu.Unlock()
+	replayPlayers = map[string]*replayPlayer{}
+}
+
+// next returns the response for the given mode and iteration: an unused
+// response pinned to that iteration wins, otherwise the next unused
+// unpinned response for the mode.
+func (p *replayPlayer) next(mode string, iteration int) (replayResponse, bool) {
+	matchesMode := func(r replayResponse) bool {
+		return r.Mode == "" || strings.EqualFold(r.Mode, mode)
+	}
+	for i, r := range p.responses {
+		if !p.used[i] && matchesMode(r) && r.Iteration > 0 && r.Iteration == iteration {
+			p.used[i] = true
+			return r, true
+		}
+	}
+	for i, r := range p.responses {
+		if !p.used[i] && matchesMode(r) && r.Iteration == 0 {
+			p.used[i] = true
+			return r, true
+		}
+	}
+	return replayResponse{}, false
+}
+
+func loadReplayPlayer(path string) (*replayPlayer, error) {
+	replayMu.Lock()
+	defer replayMu.Unlock()
+	if player, ok := replayPlayers[path]; ok {
+		return player, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replay fixture: %w", err)
+	}
+	responses, err := parseReplayFixture(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse replay fixture %s: %w", path, err)
+	}
+	player := &replayPlayer{responses: responses, used: make([]bool, len(responses))}
+	replayPlayers[path] = player
+	return player, nil
+}
+
+// parseReplayFixture accepts JSONL (one response per line), a JSON array, or
+// YAML with a top-level list or a "responses:" list.
+func parseReplayFixture(path string, data []byte) ([]replayResponse, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+	var responses []replayResponse
+	switch {
+	case trimmed[0] == '[':
+		if err := json.Unmarshal(trimmed, &responses); err != nil {
+			return nil, err
+		}
+	case trimmed[0] == '{' || strings.EqualFold(filepath.Ext(path), ".jsonl"):
+		for i, line := range strings.Split(string(trimmed), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			var r replayResponse
+			if err := json.Unmarshal([]byte(line), &r); err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			responses = append(responses, r)
+		}
+	default:
+		doc, err := parseYAMLDocument(string(data))
+		if err != nil {
+			return nil, err
+		}
+		if m, ok := doc.(map[string]interface{}); ok {
+			doc = m["responses"]
+		}
+		encoded, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(encoded, &responses); err != nil {
+			return nil, err
+		}
+	}
+	return responses, nil
+}
+
+// runReplayHarness plays back the next scripted response: it applies edits,
+// makes the commit, writes the output like a harness would and returns the
+// scripted exit status.
+func runReplayHarness(prompt, harnessArgs string, logFile *os.File, runner runtimeExec) (string, error) {
+	args, err := splitArgs(harnessArgs)
+	if err != nil {
+		return "", err
+	}
+	if len(args) == 0 {
+		return "", fmt.Errorf("%s requires a fixture path in harness_args", replayHarnessName)
+	}
+	player, err := loadReplayPlayer(args[0])
+	if err != nil {
+		return "", err
+	}
+	replayMu.Lock()
+	iteration := runner.runIteration()
+	resp, ok := player.next(runner.Mode, iteration)
+	replayMu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("replay fixture %s has no response for mode %q iteration %d", args[0], runner.Mode, iteration)
+	}
+
+	if resp.ExpectPrompt != "" && !strings.Contains(prompt, resp.ExpectPrompt) {
+		output := fmt.Sprintf("rauf-replay: prompt does not contain %q\n", resp.ExpectPrompt)
+		writeReplayOutput(output, logFile, runner)
+		return output, replayExitError{code: 1}
+	}
+	for _, edit := range resp.Edits {
+		if err := applyReplayEdit(runner.WorkDir, edit); err != nil {
+			return "", err
+		}
+	}
+	if resp.Commit != "" {
+		if _, err := gitExec(gitDirArgs(runner.WorkDir, "add", "-A")...); err != nil {
+			return "", fmt.Errorf("replay commit failed: %w", err)
+		}
+		if _, err := gitExec(gitDirArgs(runner.WorkDir, "commit", "-m", resp.Commit)...); err != nil {
+			return "", fmt.Errorf("replay commit failed: %w", err)
+		}
+	}
+
+	output := replayOutput(resp)
+	writeReplayOutput(output, logFile, runner)
+	if resp.ExitCode != 0 {
+		return output, replayExitError{code: resp.ExitCode}
+	}
+	return output, nil
+}
+
+func replayOutput(resp replayResponse) string {
+	var b strings.Builder
+	if resp.Output != "" {
+		b.WriteString(resp.Output)
+		if !strings.HasSuffix(resp.Output, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	if resp.Hypothesis != "" {
+		b.WriteString("HYPOTHESIS: " + resp.Hypothesis + "\n")
+	}
+	if resp.Different != "" {
+		b.WriteString("DIFFERENT_THIS_TIME: " + resp.Different + "\n")
+	}
+	if resp.Question != "" {
+		b.WriteString("RAUF_QUESTION: " + resp.Question + "\n")
+	}
+	if resp.Complete {
+		b.WriteString("RAUF_COMPLETE\n")
+	}
+	return b.String()
+}
+
+func writeReplayOutput(output string, logFile *os.File, runner runtimeExec) {
+	if logFile != nil {
+		_, _ = io.WriteString(logFile, output)
+	}
+	if !runner.Quiet {
+		_, _ = io.WriteString(newFilteringWriter(os.Stdout, "RAUF_QUESTION:"), output)
+	}
+}
+
+func applyReplayEdit(dir string, edit replayEdit) error {
+	if edit.Delete {
+		path, err := workspacePath(edit.Path)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(filepath.Join(dir, path)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return writeWorkspaceFile(dir, edit.Path, edit.Content, edit.Append)
+}
+
+// workspacePath cleans a harness-supplied path and rejects paths that would
+// escape the workspace.
+func workspacePath(raw string) (string, error) {
+	path := filepath.Clean(raw)
+	if raw == "" || filepath.IsAbs(path) || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path must be relative to the workspace: %q", raw)
+	}
+	return path, nil
+}
+
+// writeWorkspaceFile writes (or appends) content to a path relative to the
+// workspace dir ("" for the current directory), creating parent directories.
+func writeWorkspaceFile(dir, raw, content string, appendContent bool) error {
+	path, err := workspacePath(raw)
+	if err != nil {
+		return err
+	}
+	path = filepath.Join(dir, path)
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendContent {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// parseYAMLDocument parses the YAML subset used by fixtures: nested maps and
+// lists, plain or quoted scalars, and "|" / ">" block scalars.
+func parseYAMLDocument(data string) (interface{}, error) {
+	p := &yamlDocParser{lines: strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")}
+	p.skipBlank()
+	if p.pos >= len(p.lines) {
+		return nil, nil
+	}
+	value, err := p.parseNode(p.indent(p.pos))
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.pos+1)
+	}
+	return value, nil
+}
+
+type yamlDocParser struct {
+	lines []string
+	pos   int
+}
+
+func (p *yamlDocParser) indent(i int) int {
+	line := p.lines[i]
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func (p *yamlDocParser) skipBlank() {
+	for p.pos < len(p.lines) {
+		trimmed := strings.TrimSpace(p.lines[p.pos])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") && trimmed != "---" {
+			return
+		}
+		p.pos++
+	}
+}
+
+func isYAMLListItem(trimmed string) bool {
+	return trimmed == "-" || strings.HasPrefix(trimmed, "- ")
+}
+
+func (p *yamlDocParser) parseNode(indent int) (interface{}, error) {
+	if isYAMLListItem(strings.TrimSpace(p.lines[p.pos])) {
+		return p.parseList(indent)
+	}
+	return p.parseMap(indent)
+}
+
+func (p *yamlDocParser) parseList(indent int) (interface{}, error) {
+	list := []interface{}{}
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.lines) || p.indent(p.pos) != indent {
+			return list, nil
+		}
+		trimmed := strings.TrimSpace(p.lines[p.pos])
+		if !isYAMLListItem(trimmed) {
+			return list, nil
+		}
+		rest := strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))
+		if rest == "" {
+			p.pos++
+			p.skipBlank()
+			if p.pos >= len(p.lines) || p.indent(p.pos) <= indent {
+				list = append(list, nil)
+				continue
+			}
+			item, err := p.parseNode(p.indent(p.pos))
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+			continue
+		}
+		if _, _, ok := splitYAMLKeyValue(rest); ok && rest[0] != '"' && rest[0] != '\'' {
+			// "- key: value" starts a map whose keys align with "key".
+			itemIndent := indent + strings.Index(p.lines[p.pos][indent:], rest)
+			p.lines[p.pos] = strings.Repeat(" ", itemIndent) + rest
+			item, err := p.parseMap(itemIndent)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+			continue
+		}
+		list = append(list, yamlScalar(rest))
+		p.pos++
+	}
+}
+
+func (p *yamlDocParser) parseMap(indent int) (interface{}, error) {
+	m := map[string]interface{}{}
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.lines) || p.indent(p.pos) != indent {
+			return m, nil
+		}
+		trimmed := strings.TrimSpace(p.lines[p.pos])
+		if isYAMLListItem(trimmed) {
+			return m, nil
+		}
+		key, value, ok := splitYAMLKeyValue(trimmed)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value", p.pos+1)
+		}
+		key = stripQuotes(key)
+		p.pos++
+		switch {
+		case value == "|" || value == "|-" || value == ">" || value == ">-":
+			m[key] = p.blockScalar(indent, value)
+		case value == "" || strings.HasPrefix(value, "#"):
+			p.skipBlank()
+			if p.pos < len(p.lines) && (p.indent(p.pos) > indent || (p.indent(p.pos) == indent && isYAMLListItem(strings.TrimSpace(p.lines[p.pos])))) {
+				child, err := p.parseNode(p.indent(p.pos))
+				if err != nil {
+					return nil, err
+				}
+				m[key] = child
+			} else {
+				m[key] = nil
+			}
+		default:
+			m[key] = yamlScalar(value)
+		}
+	}
+}
+
+// blockScalar collects the lines indented deeper than the parent key.
+func (p *yamlDocParser) blockScalar(parentIndent int, style string) string {
+	var lines []string
+	blockIndent := -1
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+		ind := p.indent(p.pos)
+		if ind <= parentIndent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = ind
+		}
+		if ind < blockIndent {
+			break
+		}
+		lines = append(lines, line[blockIndent:])
+		p.pos++
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	sep := "\n"
+	if strings.HasPrefix(style, ">") {
+		sep = " "
+	}
+	text := strings.Join(lines, sep)
+	if !strings.HasSuffix(style, "-") && text != "" {
+		text += "\n"
+	}
+	return text
+}
+
+func yamlScalar(value string) interface{} {
+	if value == "" {
+		return ""
+	}
+	if value[0] == '"' || value[0] == '\'' {
+		text := stripQuotesAndComments(value)
+		if value[0] == '"' {
+			if unquoted, err := strconv.Unquote(`"` + text + `"`); err == nil {
+				return unquoted
+			}
+		}
+		return text
+	}
+	value = stripQuotesAndComments(value)
+	switch value {
+	case "null", "~":
+		return nil
+	case "true", "false":
+		return value == "true"
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		return n
+	}
+	return value
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseReplayFixtureYAML(t *testing.T) {
+	data := `# scripted run
+responses:
+  - mode: build
+    iteration: 2
+    hypothesis: "missing newline"
+    edits:
+      - path: src/a.txt
+        content: |
+          hello
+          world
+      - path: old.txt
+        delete: true
+    commit: "T1: add a"
+    complete: true
+  - output: |-
+      thinking
+    exit_code: 3
+`
+	responses, err := parseReplayFixture("fixture.yaml", []byte(data))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(responses) != 2 {
+		t.Fatalf("expected 2 responses, got %+v", responses)
+	}
+	first := responses[0]
+	if first.Mode != "build" || first.Iteration != 2 || first.Hypothesis != "missing newline" || first.Commit != "T1: add a" || !first.Complete {
+		t.Fatalf("unexpected first response: %+v", first)
+	}
+	if len(first.Edits) != 2 || first.Edits[0].Content != "hello\nworld\n" || !first.Edits[1].Delete {
+		t.Fatalf("unexpected edits: %+v", first.Edits)
+	}
+	if responses[1].Output != "thinking" || responses[1].ExitCode != 3 {
+		t.Fatalf("unexpected second response: %+v", responses[1])
+	}
+}
+
+func TestParseReplayFixtureJSONL(t *testing.T) {
+	data := `{"mode":"plan","output":"planned","complete":true}
+# comment
+{"question":"Which database?"}
+`
+	responses, err := parseReplayFixture("fixture.jsonl", []byte(data))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(responses) != 2 || responses[0].Mode != "plan" || responses[1].Question != "Which database?" {
+		t.Fatalf("unexpected responses: %+v", responses)
+	}
+	if _, err := parseReplayFixture("bad.jsonl", []byte("{not json}\n")); err == nil {
+		t.Fatalf("expected error for invalid JSONL")
+	}
+}
+
+func TestReplayPlayerSelection(t *testing.T) {
+	player := &replayPlayer{responses: []replayResponse{
+		{Mode: "plan", Output: "plan-1"},
+		{Output: "any-1"},
+		{Mode: "build", Iteration: 2, Output: "build-2"},
+		{Mode: "build", Output: "build-next"},
+	}}
+	player.used = make([]bool, len(player.responses))
+
+	want := []struct {
+		mode      string
+		iteration int
+		output    string
+	}{
+		{"build", 1, "any-1"},
+		{"build", 2, "build-2"},
+		{"build", 3, "build-next"},
+		{"plan", 1, "plan-1"},
+	}
+	for _, tc := range want {
+		resp, ok := player.next(tc.mode, tc.iteration)
+		if !ok || resp.Output != tc.output {
+			t.Fatalf("next(%s, %d) = %q ok=%t, want %q", tc.mode, tc.iteration, resp.Output, ok, tc.output)
+		}
+	}
+	if _, ok := player.next("build", 4); ok {
+		t.Fatalf("expected fixture to be exhausted")
+	}
+}
+
+func TestRunReplayHarnessExitCodeAndExpectPrompt(t *testing.T) {
+	dir := t.TempDir()
+	chdirTemp(t, dir)
+	resetReplayPlayers()
+	defer resetReplayPlayers()
+
+	fixture := `[{"output":"Error: overloaded","exit_code":7},{"expect_prompt":"## Task","complete":true}]`
+	if err := os.WriteFile("replay.json", []byte(fixture), 0o644); err != nil {
+		t.Fatalf("write fixture failed: %v", err)
+	}
+	runner := runtimeExec{Quiet: true, Mode: "build", Iteration: 1}
+
+	out, err := runHarnessOnce(context.Background(), "prompt", replayHarnessName, "replay.json", nil, runner)
+	var exitErr interface{ ExitCode() int }
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 7 || !strings.Contains(out, "overloaded") {
+		t.Fatalf("expected exit code 7, got out=%q err=%v", out, err)
+	}
+	if got := classifyHarnessFailure(fallbackPolicy{ExitCodes: []int{7}}, out, err); got != "exit_code:7" {
+		t.Fatalf("expected replay exit code to be classified, got %q", got)
+	}
+
+	out, err = runHarnessOnce(context.Background(), "prompt", replayHarnessName, "replay.json", nil, runner)
+	if err == nil || !strings.Contains(out, "does not contain") {
+		t.Fatalf("expected prompt expectation failure, got out=%q err=%v", out, err)
+	}
+
+	if _, err := runHarnessOnce(context.Background(), "prompt", replayHarnessName, "", nil, runner); err == nil {
+		t.Fatalf("expected error without fixture path")
+	}
+}
+
+func TestRunReplayHarnessHypothesisAndRunIteration(t *testing.T) {
+	dir := t.TempDir()
+	chdirTemp(t, dir)
+	resetReplayPlayers()
+	defer resetReplayPlayers()
+
+	fixture := `[{"iteration":3,"hypothesis":"flaky fixture","different_this_time":"pin the seed"},{"output":"unpinned"}]`
+	if err := os.WriteFile("replay.json", []byte(fixture), 0o644); err != nil {
+		t.Fatalf("write fixture failed: %v", err)
+	}
+	// Third iteration of a strategy run: the step's own loop is at 1.
+	runner := runtimeExec{Quiet: true, Mode: "build", Iteration: 1, IterationBase: 2}
+	out, err := runHarnessOnce(context.Background(), "prompt", replayHarnessName, "replay.json", nil, runner)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasRequiredHypothesis(out) {
+		t.Fatalf("expected a complete hypothesis, got %q", out)
+	}
+}
+
+func TestRunModeWithReplayHarness(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+	chdirTemp(t, dir)
+	resetReplayPlayers()
+	defer resetReplayPlayers()
+
+	plan := "# Plan\n- [ ] T1: add greeting\n  - Verify: grep -q world greeting.txt\n"
+	fixture := `responses:
+  - mode: build
+    iteration: 1
+    output: "Will change: greeting.txt"
+    edits:
+      - path: greeting.txt
+        content: "hello\n"
+    commit: "T1: add greeting"
+  - mode: build
+    iteration: 2
+    hypothesis: greeting was missing the word world
+    edits:
+      - path: greeting.txt
+        content: "hello world\n"
+      - path: PLAN.md
+        content: |
+          # Plan
+          - [x] T1: add greeting
+            - Verify: grep -q world greeting.txt
+    commit: "T1: fix greeting"
+    complete: true
+`
+	files := map[string]string{
+		"PLAN.md":         plan,
+		"PROMPT_build.md": "build",
+		"replay.yaml":     fixture,
+		".gitignore":      "logs/\n.rauf/\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+	}
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-m", "setup")
+
+	cfg := modeConfig{mode: "build", promptFile: "PROMPT_build.md", maxIterations: 4}
+	fileCfg := runtimeConfig{NoProgressIters: 5, Harness: replayHarnessName}
+	report := &RunReport{}
+	res, err := runMode(context.Background(), cfg, fileCfg, runtimeExec{Quiet: true}, raufState{}, true, "", "PLAN.md", replayHarnessName, "replay.yaml", true, "logs", false, 0, 0, 0, false, nil, 0, nil, nil, report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.ExitReason != "completion_contract_satisfied" {
+		t.Fatalf("unexpected exit reason %q (iterations %+v)", res.ExitReason, report.Iterations)
+	}
+	if len(report.Iterations) != 2 || report.Iterations[0].VerifyStatus != "fail" || report.Iterations[1].VerifyStatus != "pass" {
+		t.Fatalf("unexpected iterations: %+v", report.Iterations)
+	}
+	// The failed first attempt is soft reset, so only the fix remains committed.
+	subjects := runGit(t, dir, "log", "--format=%s", "-2")
+	if subjects != "T1: fix greeting\nsetup" {
+		t.Fatalf("unexpected commits: %q", subjects)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// retryRule is one parsed retry_match entry. Supported forms:
+//
+//	rate limit        substring of stdout+stderr (case-insensitive)
+//	stderr:overloaded substring of stderr only
+//	re:too many .*    regex over stdout+stderr (stderr:re:... for stderr only)
+//	exit:75           harness exit code
+//	*                 any failure
+type retryRule struct {
+	Raw      string
+	Stderr   bool
+	ExitCode int
+	Token    string
+	re       *regexp.Regexp
+}
+
+// maxRetryAfter caps server-provided retry hints.
+const maxRetryAfter = 10 * time.Minute
+
+// harnessRunError carries the stderr of a failed harness run so retry rules
+// can match on it separately from stdout.
+type harnessRunError struct {
+	err    error
+	stderr string
+}
+
+func (e *harnessRunError) Error() string {
+	return e.err.Error()
+}
+
+func (e *harnessRunError) Unwrap() error {
+	return e.err
+}
+
+func parseRetryRule(raw string) (retryRule, bool) {
+	raw = strings.TrimSpace(raw)
+	rule := retryRule{Raw: raw}
+	value := raw
+	if strings.HasPrefix(strings.ToLower(value), "exit:") {
+		code, err := strconv.Atoi(strings.TrimSpace(value[len("exit:"):]))
+		if err != nil {
+			return retryRule{}, false
+		}
+		rule.ExitCode = code
+		return rule, true
+	}
+	if strings.HasPrefix(strings.ToLower(value), "stderr:") {
+		rule.Stderr = true
+		value = strings.TrimSpace(value[len("stderr:"):])
+	}
+	if strings.HasPrefix(strings.ToLower(value), "re:") {
+		re, err := regexp.Compile("(?i)" + strings.TrimSpace(value[len("re:"):]))
+		if err != nil {
+			return retryRule{}, false
+		}
+		rule.re = re
+		return rule, true
+	}
+	if value == "" {
+		return retryRule{}, false
+	}
+	rule.Token = strings.ToLower(value)
+	return rule, true
+}
+
+// matches reports whether the rule applies to a failed run.
+func (r retryRule) matches(output, stderr string, err error) bool {
+	if r.Token == "*" {
+		return true
+	}
+	if r.ExitCode != 0 {
+		var exitErr interface{ ExitCode() int }
+		return errors.As(err, &exitErr) && exitErr.ExitCode() == r.ExitCode
+	}
+	text := output
+	if r.Stderr {
+		text = stderr
+	}
+	if r.re != nil {
+		return r.re.MatchString(text)
+	}
+	return strings.Contains(strings.ToLower(text), r.Token)
+}
+
+// matchRetryRules returns the first retry_match entry that applies.
+func matchRetryRules(match []string, output string, err error) (string, bool) {
+	stderr := ""
+	var runErr *harnessRunError
+	if errors.As(err, &runErr) {
+		stderr = runErr.stderr
+	}
+	for _, raw := range match {
+		rule, ok := parseRetryRule(raw)
+		if !ok {
+			continue
+		}
+		if rule.matches(output, stderr, err) {
+			return rule.Raw, true
+		}
+	}
+	return "", false
+}
+
+var retryAfterPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)retry[-_ ]after(-ms)?["']?\s*[:=]?\s*"?(\d+(?:\.\d+)?)\s*(ms|milliseconds?|s|secs?|seconds?|m|mins?|minutes?)?\b`),
+	regexp.MustCompile(`(?i)try again in\s+(\d+(?:\.\d+)?)\s*(ms|milliseconds?|s|secs?|seconds?|m|mins?|minutes?)?\b`),
+	regexp.MustCompile(`(?i)try again in\s+((?:\d+(?:\.\d+)?(?:h|m|s|ms))+)\b`),
+}
+
+// parseRetryAfterHint finds the last Retry-After or "try again in N" hint in
+// harness output. Bare numbers are seconds.
+func parseRetryAfterHint(output string) (time.Duration, bool) {
+	best, bestPos := time.Duration(0), -1
+	consider := func(pos int, d time.Duration) {
+		if d > 0 && pos > bestPos {
+			best, bestPos = d, pos
+		}
+	}
+	for _, loc := range retryAfterPatterns[0].FindAllStringSubmatchIndex(output, -1) {
+		value := output[loc[4]:loc[5]]
+		unit := ""
+		if loc[6] >= 0 {
+			unit = output[loc[6]:loc[7]]
+		}
+		if loc[2] >= 0 && unit == "" {
+			unit = "ms"
+		}
+		consider(loc[0], hintDuration(value, unit))
+	}
+	// Go-style durations ("1m30s") first so they win over the bare-number form.
+	for _, loc := range retryAfterPatterns[2].FindAllStringSubmatchIndex(output, -1) {
+		if d, err := time.ParseDuration(output[loc[2]:loc[3]]); err == nil {
+			consider(loc[0], d)
+		}
+	}
+	for _, loc := range retryAfterPatterns[1].FindAllStringSubmatchIndex(output, -1) {
+		unit := ""
+		if loc[4] >= 0 {
+			unit = output[loc[4]:loc[5]]
+		}
+		consider(loc[0], hintDuration(output[loc[2]:loc[3]], unit))
+	}
+	if bestPos < 0 {
+		return 0, false
+	}
+	if best > maxRetryAfter {
+		best = maxRetryAfter
+	}
+	return best, true
+}
+
+func hintDuration(value, unit string) time.Duration {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	scale := time.Second
+	switch u := strings.ToLower(unit); {
+	case strings.HasPrefix(u, "ms") || strings.HasPrefix(u, "milli"):
+		scale = time.Millisecond
+	case strings.HasPrefix(u, "m"):
+		scale = time.Minute
+	}
+	return time.Duration(n * float64(scale))
+}
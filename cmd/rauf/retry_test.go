@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMatchRetryRules(t *testing.T) {
+	exitErr := &harnessRunError{err: replayExitError{code: 75}, stderr: "Error: Overloaded, please retry\n"}
+	output := "running tests... timeout in TestFoo\nError: Overloaded, please retry\n"
+
+	tests := []struct {
+		match []string
+		err   error
+		want  string
+	}{
+		{[]string{"timeout"}, exitErr, "timeout"},
+		{[]string{"stderr:timeout", "stderr:overloaded"}, exitErr, "stderr:overloaded"},
+		{[]string{"exit:1", "exit:75"}, exitErr, "exit:75"},
+		{[]string{`re:overload(ed)?, please`}, exitErr, `re:overload(ed)?, please`},
+		{[]string{`stderr:re:^timeout`}, exitErr, ""},
+		{[]string{"stderr:overloaded"}, errors.New("plain"), ""},
+		{[]string{"re:(", "*"}, nil, "*"},
+	}
+	for _, tc := range tests {
+		got, ok := matchRetryRules(tc.match, output, tc.err)
+		if got != tc.want || ok != (tc.want != "") {
+			t.Errorf("matchRetryRules(%v) = %q, %t; want %q", tc.match, got, ok, tc.want)
+		}
+	}
+}
+
+func TestParseRetryAfterHint(t *testing.T) {
+	tests := []struct {
+		output string
+		want   time.Duration
+	}{
+		{"HTTP 429\nRetry-After: 12\n", 12 * time.Second},
+		{`{"error":{"message":"Rate limit reached. Please try again in 6.5s."}}`, 6500 * time.Millisecond},
+		{"Please try again in 1m30s", 90 * time.Second},
+		{"retry-after-ms: 250", 250 * time.Millisecond},
+		{"try again in 2 minutes", 2 * time.Minute},
+		{"Retry-After: 5\n... later: try again in 20 seconds", 20 * time.Second},
+		{"retry after 3600", maxRetryAfter},
+	}
+	for _, tc := range tests {
+		got, ok := parseRetryAfterHint(tc.output)
+		if !ok || got != tc.want {
+			t.Errorf("parseRetryAfterHint(%q) = %s, %t; want %s", tc.output, got, ok, tc.want)
+		}
+	}
+	if _, ok := parseRetryAfterHint("timeout while waiting"); ok {
+		t.Errorf("expected no hint")
+	}
+}
+
+func TestDefaultRetryMatchIgnoresStdout(t *testing.T) {
+	stdoutOnly := &harnessRunError{err: replayExitError{code: 1}}
+	if got, ok := matchRetryRules(defaultRetryMatch, "--- FAIL: TestFoo (timeout after 30s)\nexpected status 429\n", stdoutOnly); ok {
+		t.Fatalf("expected stdout-only matches not to retry, got %q", got)
+	}
+	tests := []struct {
+		stderr string
+		want   string
+	}{
+		{"API Error: Rate limit exceeded\n", "stderr:rate limit"},
+		{"HTTP 429 Too Many Requests\n", "stderr:429"},
+		{"Error: request timed out\n", `stderr:re:\btimed? ?out\b`},
+		{"connect: timeout\n", `stderr:re:\btimed? ?out\b`},
+	}
+	for _, tc := range tests {
+		err := &harnessRunError{err: replayExitError{code: 1}, stderr: tc.stderr}
+		if got, ok := matchRetryRules(defaultRetryMatch, "", err); !ok || got != tc.want {
+			t.Errorf("stderr %q: got %q, %t; want %q", tc.stderr, got, ok, tc.want)
+		}
+	}
+
+	cfg := runtimeConfig{}
+	if err := parseConfigBytes([]byte(configTemplate), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.RetryMatch, defaultRetryMatch) {
+		t.Fatalf("expected the config template to carry the defaults, got %q", cfg.RetryMatch)
+	}
+}
+
+func TestRunHarnessLogsRetries(t *testing.T) {
+	dir := t.TempDir()
+	logFile, err := os.Create(filepath.Join(dir, "run.jsonl"))
+	if err != nil {
+		t.Fatalf("create log failed: %v", err)
+	}
+	defer logFile.Close()
+
+	orig := runHarnessOnce
+	defer func() { runHarnessOnce = orig }()
+	calls := 0
+	runHarnessOnce = func(ctx context.Context, prompt string, harness, harnessArgs string, logFile *os.File, runner runtimeExec) (string, error) {
+		calls++
+		if calls == 1 {
+			out := "Rate limited. Retry-After: 0.01\n"
+			return out, &harnessRunError{err: replayExitError{code: 75}, stderr: out}
+		}
+		return "ok", nil
+	}
+
+	retry := retryConfig{Enabled: true, MaxAttempts: 2, BackoffBase: time.Hour, Match: []string{"exit:75"}}
+	runner := runtimeExec{Quiet: true, Mode: "build", Iteration: 4}
+	res, err := runHarness(context.Background(), "prompt", "harness", "", logFile, retry, runner)
+	if err != nil || res.RetryCount != 1 || res.RetryReason != "exit:75" {
+		t.Fatalf("unexpected result: %+v err=%v", res, err)
+	}
+
+	if _, err := logFile.Seek(0, 0); err != nil {
+		t.Fatalf("seek failed: %v", err)
+	}
+	scanner := bufio.NewScanner(logFile)
+	var entries []logEntry
+	for scanner.Scan() {
+		var entry logEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected one retry entry, got %+v", entries)
+	}
+	e := entries[0]
+	if e.Type != "harness_retry" || e.Attempt != 1 || e.RetryRule != "exit:75" || e.ExitCode != 75 || e.Delay != "10ms" || e.RetryAfter != "10ms" || e.Iteration != 4 {
+		t.Fatalf("unexpected retry entry: %+v", e)
+	}
+}
+
+func TestParseConfigRetryRules(t *testing.T) {
+	cfg := runtimeConfig{}
+	data := "retry_match:\n  - exit:75   # exit code\n  - \"stderr:re:^error: 5\\d\\d\"\n"
+	if err := parseConfigBytes([]byte(data), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(cfg.RetryMatch) != 2 || cfg.RetryMatch[0] != "exit:75" || cfg.RetryMatch[1] != `stderr:re:^error: 5\d\d` {
+		t.Fatalf("unexpected retry rules: %q", cfg.RetryMatch)
+	}
+}
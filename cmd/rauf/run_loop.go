@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/signal"
 	"strings"
 	"time"
 )
@@ -23,49 +22,83 @@ type iterationResult struct {
 const completionSentinel = "RAUF_COMPLETE"
 
 var runStrategy = func(ctx context.Context, cfg modeConfig, fileCfg runtimeConfig, runner runtimeExec, state raufState, gitAvailable bool, branch, planPath, harness, harnessArgs string, noPush bool, logDir string, retryEnabled bool, retryMaxAttempts int, retryBackoffBase, retryBackoffMax time.Duration, retryJitter bool, retryMatch []string, stdin io.Reader, stdout io.Writer, report *RunReport) error {
-	lastResult := iterationResult{}
-	for _, step := range fileCfg.Strategy {
-		if !shouldRunStep(step, lastResult) {
+	steps := fileCfg.Strategy
+	if err := validateStrategy(steps); err != nil {
+		return err
+	}
+	index := strategyStepIndex(steps)
+	maxTotal := fileCfg.StrategyMaxIterations
+	if maxTotal <= 0 {
+		maxTotal = defaultStrategyMaxIterations
+	}
+	checkpoint := runCheckpointFrom(ctx)
+	startStep, startIteration, sc := checkpoint.strategyPosition()
+	sc.State = state
+	sc.PlanPath = planPath
+	if sc.SpecsHash == "" {
+		sc.SpecsHash = specsFingerprint()
+	}
+	for idx := startStep; idx < len(steps); {
+		step := steps[idx]
+		// A step resumed mid-way already passed its `if` condition.
+		resumed := idx == startStep && startIteration > 0
+		if !resumed && !shouldRunStep(step, sc) {
+			idx++
+			checkpoint.recordStep(idx, 0, sc)
 			continue
 		}
-		modeCfg := cfg
-		modeCfg.mode = step.Mode
-		modeCfg.promptFile = promptForMode(step.Mode)
+		run := resolveStrategyStep(idx, step, cfg, fileCfg, harness, harnessArgs, noPush)
+		if !runner.Quiet {
+			fmt.Println(run.cfg.step.describe(step.Mode))
+		}
+		run.cfg.maxIterations = 1
 		maxIterations := step.Iterations
 		if maxIterations <= 0 {
 			maxIterations = 1
 		}
-		modeCfg.maxIterations = 1
 		// Reset NoProgress counter at the start of each strategy step
 		stepNoProgress := 0
-		for i := 0; i < maxIterations; i++ {
+		first := 0
+		if resumed {
+			first = startIteration
+			stepNoProgress = sc.Last.NoProgress
+			startIteration = 0
+		}
+		for i := first; i < maxIterations; i++ {
 			if ctx.Err() != nil {
 				return ctx.Err()
 			}
-			result, err := runMode(ctx, modeCfg, fileCfg, runner, state, gitAvailable, branch, planPath, harness, harnessArgs, noPush, logDir, retryEnabled, retryMaxAttempts, retryBackoffBase, retryBackoffMax, retryJitter, retryMatch, stepNoProgress, stdin, stdout, report)
+			if sc.Iterations >= maxTotal {
+				fmt.Printf("Strategy iteration cap reached: %d\n", maxTotal)
+				report.Iterations = append(report.Iterations, IterationStats{Mode: step.Mode, ExitReason: strategyIterationCapReason})
+				return nil
+			}
+			runner.IterationBase = sc.Iterations
+			result, err := runMode(ctx, run.cfg, run.fileCfg, runner, sc.State, gitAvailable, branch, planPath, run.harness, run.harnessArgs, run.noPush, logDir, retryEnabled, retryMaxAttempts, retryBackoffBase, retryBackoffMax, retryJitter, retryMatch, stepNoProgress, stdin, stdout, report)
 			if err != nil {
 				return err
 			}
-			// Update state to reflect changes from runMode (critical for strategies)
-			// Wait, runMode returns result, but state is passed by value.
-			// Ideally runMode should accept *raufState or return updated state.
-			// Current arch seems to assume state is re-loaded or mutable?
-			// Actually state is modified inside runMode loop but `state` here is a local copy?
-			// runMode loop updates its local copy.
-			// If we run multiple modes in strategy, we should propagate state.
-			// But runMode doesn't return state.
-			// This is a pre-existing issue or I am missing something.
-			// But for now I just match signature.
-
-			lastResult = result
+			// runMode works on its own copy of the state and saves it after
+			// every iteration; reload it so escalation, recovery mode,
+			// hypotheses and assumptions carry over to the next step.
+			sc.observe(result, reloadState(sc.State))
 			stepNoProgress = result.NoProgress
+			checkpoint.recordStep(idx, i+1, sc)
+			if result.ExitReason == "budget_exceeded" || result.ExitReason == interruptExitReason || result.ExitReason == stoppedByUserReason {
+				return nil
+			}
 			if result.ExitReason == "no_progress" {
 				break
 			}
-			if !shouldContinueUntil(step, result) {
+			if !shouldContinueUntil(step, sc) {
 				break
 			}
 		}
+		if step.Mode == "plan" {
+			sc.SpecsHash = specsFingerprint()
+		}
+		idx = nextStrategyStep(steps, idx, index, &sc)
+		checkpoint.recordStep(idx, 0, sc)
 	}
 	return nil
 }
@@ -73,6 +106,8 @@ var runStrategy = func(ctx context.Context, cfg modeConfig, fileCfg runtimeConfi
 var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeConfig, runner runtimeExec, state raufState, gitAvailable bool, branch, planPath, harness, harnessArgs string, noPush bool, logDir string, retryEnabled bool, retryMaxAttempts int, retryBackoffBase, retryBackoffMax time.Duration, retryJitter bool, retryMatch []string, startNoProgress int, stdin io.Reader, stdout io.Writer, report *RunReport) (iterationResult, error) {
 	iteration := 0
 	noProgress := startNoProgress
+	// fileCfg.ModelDefault is replaced by each iteration's base model.
+	defaultModel := fileCfg.ModelDefault
 	maxNoProgress := fileCfg.NoProgressIters
 	if maxNoProgress <= 0 {
 		maxNoProgress = 2
@@ -84,6 +119,8 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 	excludeDirs := []string{".git", ".rauf", logDirName}
 
 	lastResult := iterationResult{}
+	// runStartHead bounds the pre-push commit check to commits made by this run.
+	runStartHead := ""
 
 	// Print welcome message for architect and plan modes
 	if iteration == 0 && !runner.Quiet {
@@ -111,6 +148,7 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 		Iteration: iteration + 1,
 		Mode:      cfg.mode,
 		Model:     state.CurrentModel,
+		Step:      cfg.step,
 	}
 
 	for {
@@ -124,6 +162,23 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 			report.Iterations = append(report.Iterations, iterStats)
 			break
 		}
+		if budget, exceeded := budgetExceeded(fileCfg, report, ""); exceeded {
+			fmt.Printf("Budget exceeded: %s\n", budget)
+			iterStats.ExitReason = "budget_exceeded"
+			iterStats.Duration = time.Since(startIter).String()
+			report.Iterations = append(report.Iterations, iterStats)
+			lastResult.ExitReason = "budget_exceeded"
+			return lastResult, nil
+		}
+		if reason, err := waitForLoopControl(parentCtx); err != nil {
+			return lastResult, err
+		} else if reason != "" {
+			iterStats.ExitReason = reason
+			iterStats.Duration = time.Since(startIter).String()
+			report.Iterations = append(report.Iterations, iterStats)
+			lastResult.ExitReason = reason
+			return lastResult, nil
+		}
 		iterNum := iteration + 1
 
 		if cfg.mode == "plan" || cfg.mode == "build" {
@@ -149,6 +204,12 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 				report.Iterations = append(report.Iterations, iterStats)
 				return iterationResult{}, fmt.Errorf("unable to read git HEAD: %w", err)
 			}
+			if runStartHead == "" {
+				runStartHead = headBefore
+				if cp := runCheckpointFrom(parentCtx); cp != nil && cp.StartHead != "" {
+					runStartHead = cp.StartHead
+				}
+			}
 		}
 
 		planHashBefore := ""
@@ -245,8 +306,12 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 
 		fingerprintBefore := ""
 		fingerprintBeforePlanExcluded := ""
+		var stampsBefore map[string]fileStamp
 		if !gitAvailable {
 			fingerprintBefore = workspaceFingerprint(".", excludeDirs, nil)
+			if cfg.mode == "build" && largeFileGuardEnabled(fileCfg) {
+				stampsBefore = workspaceStamps(".", excludeDirs)
+			}
 			if missingVerify && planPath != "" {
 				fingerprintBeforePlanExcluded = workspaceFingerprint(".", excludeDirs, []string{planPath})
 			}
@@ -288,7 +353,7 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 		capabilityMap := readAgentsCapabilityMap("AGENTS.md", maxCapabilityBytes)
 		contextFile := readContextFile(".rauf/context.md", maxContextBytes)
 
-		promptContent, promptHash, err := buildPromptContent(cfg.promptFile, promptData{
+		promptContent, promptHash, promptSections, err := buildPromptContent(cfg.promptFile, promptData{
 			Mode:                    cfg.mode,
 			PlanPath:                planPath,
 			ActiveTask:              task.TitleLine,
@@ -304,16 +369,14 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 			PriorVerification:       state.LastVerificationOutput,
 			PriorVerificationCmd:    state.LastVerificationCommand,
 			PriorVerificationStatus: state.LastVerificationStatus,
-		})
+			Backpressure:            backpressurePack,
+		}, fileCfg.PromptBudgetTokens)
 		if err != nil {
 			iterStats.ExitReason = "prompt_build_failed"
 			iterStats.Duration = time.Since(startIter).String()
 			report.Iterations = append(report.Iterations, iterStats)
 			return iterationResult{}, err
 		}
-		if backpressurePack != "" {
-			promptContent = backpressurePack + "\n\n" + promptContent
-		}
 
 		logFile, logPath, err := openLogFile(cfg.mode, logDir)
 		if err != nil {
@@ -325,16 +388,19 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 		fmt.Printf("Logs:   %s\n", logPath)
 
 		writeLogEntry(logFile, logEntry{
-			Type:       "iteration_start",
-			Mode:       cfg.mode,
-			Iteration:  iterNum,
-			VerifyCmd:  formatVerifyCommands(verifyCmds),
-			PlanHash:   planHashBefore,
-			PromptHash: promptHash,
-			Branch:     branch,
+			Type:           "iteration_start",
+			Mode:           cfg.mode,
+			Iteration:      iterNum,
+			VerifyCmd:      formatVerifyCommands(verifyCmds),
+			PlanHash:       planHashBefore,
+			PromptHash:     promptHash,
+			Branch:         branch,
+			PromptTokens:   estimateTokens(promptContent),
+			PromptSections: promptSections,
+			Step:           cfg.step,
 		})
 
-		ctx, stop := signal.NotifyContext(parentCtx, os.Interrupt)
+		ctx, stop := context.WithCancel(parentCtx)
 		retryCfg := retryConfig{
 			Enabled:     retryEnabled,
 			MaxAttempts: retryMaxAttempts,
@@ -344,90 +410,224 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 			Match:       retryMatch,
 		}
 
+		// Pick the base model for this mode and task; escalation climbs from it.
+		fileCfg.ModelDefault = defaultModel
+		var modelReason string
+		fileCfg.ModelDefault, modelReason = selectBaseModel(fileCfg, cfg.stepModel, cfg.mode, task)
+
 		// Compute effective harness args with model escalation
 		effectiveHarnessArgs := harnessArgs
 		escalated := false
 		escalationReason := ""
+		if !fileCfg.ModelEscalation.Enabled && modelReason != modelReasonDefault && fileCfg.ModelDefault != "" && !strings.Contains(harnessArgs, "{model}") {
+			// A model chosen for this mode, step or task replaces the one in harness_args.
+			effectiveHarnessArgs = applyModelChoice(harnessArgs, fileCfg.ModelFlag, fileCfg.ModelDefault, true)
+		}
 		if fileCfg.ModelEscalation.Enabled {
 			// Check if we should escalate (catch-up logic for start of iteration)
 			shouldEscalate, reason, suppressed := shouldEscalateModel(state, fileCfg)
 			if shouldEscalate {
-				if state.CurrentModel != fileCfg.ModelStrong {
-					from := state.CurrentModel
-					if from == "" {
-						from = fileCfg.ModelDefault
-					}
-					// Log the escalation immediately
-					writeLogEntry(logFile, logEntry{
-						Type:             "model_escalation",
-						FromModel:        from,
-						ToModel:          fileCfg.ModelStrong,
-						EscalationReason: reason,
-						EscalationCount:  state.EscalationCount + 1,
-						Cooldown:         fileCfg.ModelEscalation.CooldownIters,
-					})
-
-					state.CurrentModel = fileCfg.ModelStrong
-					state.EscalationCount++
-					state.MinStrongIterationsRemaining = fileCfg.ModelEscalation.CooldownIters
-					state.LastEscalationReason = reason
-					escalated = true
-					escalationReason = reason
-					fmt.Printf("Model escalation triggered: %s -> %s (reason: %s)\n",
-						fileCfg.ModelDefault, fileCfg.ModelStrong, reason)
-				}
+				event := escalateModel(&state, fileCfg, reason)
+				// Log the escalation immediately
+				writeLogEntry(logFile, event.logEntry(state.EscalationCount))
+				escalated = true
+				escalationReason = reason
+				fmt.Printf("Model escalation triggered: %s -> %s (tier %d -> %d, reason: %s)\n",
+					event.FromModel, event.ToModel, event.FromTier, event.ToTier, reason)
 			} else if suppressed != "" {
-				// Log suppression if we haven't already logged it recently?
-				// To avoid noise, we might only want to log this if something changed or meaningfully suppressed.
-				// For now, logging it allows "observability" as requested.
-				from := state.CurrentModel
-				if from == "" {
-					from = fileCfg.ModelDefault
-				}
-				writeLogEntry(logFile, logEntry{
-					Type:             "model_escalation", // keeping type same or distinct? User suggested "model_escalation" with reason/cooldown
-					FromModel:        from,
-					ToModel:          fileCfg.ModelStrong, // Targeted model
-					EscalationReason: suppressed,          // e.g. "max_escalations_reached"
-					Escalated:        false,               // Explicitly not escalated
-					Cooldown:         state.MinStrongIterationsRemaining,
-				})
+				// Log suppression for observability; Escalated stays false.
+				event := suppressedEscalation(state, fileCfg, suppressed) // e.g. "max_escalations_reached"
+				writeLogEntry(logFile, event.logEntry(state.EscalationCount))
 			}
 			// Apply model to harness args
 			model := computeEffectiveModel(state, fileCfg)
-			if model != "" {
-				effectiveHarnessArgs = applyModelChoice(harnessArgs, fileCfg.ModelFlag, model, fileCfg.ModelOverride)
+			if model != "" && !strings.Contains(harnessArgs, "{model}") {
+				effectiveHarnessArgs = applyModelChoice(harnessArgs, fileCfg.ModelFlag, model, fileCfg.ModelOverride || modelReason != modelReasonDefault)
 			}
 		}
 
-		// Run harness
-		harnessCtx := ctx
-		if cfg.AttemptTimeout > 0 {
-			var cancel context.CancelFunc
-			harnessCtx, cancel = context.WithTimeout(ctx, cfg.AttemptTimeout)
-			defer cancel()
+		// Run harness (walking the fallback chain on qualifying failures)
+		chain := harnessChain(fileCfg, harness, effectiveHarnessArgs)
+		if event, ok := tickFallbackCooldown(&state, chain); ok {
+			fmt.Fprintf(os.Stderr, "Fallback cooldown expired; returning to %s\n", event.To)
+			writeLogEntry(logFile, logEntry{
+				Type:           "harness_fallback",
+				Mode:           cfg.mode,
+				Iteration:      iterNum,
+				FromModel:      event.From,
+				ToModel:        event.To,
+				FallbackReason: event.Reason,
+			})
 		}
 
 		if !runner.Quiet {
 			fmt.Fprintf(os.Stderr, "Sending prompt to model and waiting for response...\n")
 		}
-		harnessRes, err := runHarness(harnessCtx, promptContent, harness, effectiveHarnessArgs, logFile, retryCfg, runner)
+		runner.Mode = cfg.mode
+		runner.Iteration = iterNum
+		runner.Model = computeEffectiveModel(state, fileCfg)
+		if runner.Model != fileCfg.ModelDefault {
+			modelReason = modelReasonEscalation
+			if state.LastEscalationReason != "" {
+				modelReason += ":" + state.LastEscalationReason
+			}
+		}
+		iterStats.Model = runner.Model
+		iterStats.ModelReason = modelReason
+		if runner.Model != "" {
+			writeLogEntry(logFile, logEntry{
+				Type:        "model_selection",
+				Mode:        cfg.mode,
+				Iteration:   iterNum,
+				Model:       runner.Model,
+				ModelReason: modelReason,
+			})
+			if !runner.Quiet {
+				fmt.Printf("Model:  %s (%s)\n", runner.Model, modelReason)
+			}
+		}
+		runner.TaskID = taskIDFromTitle(task.TitleLine)
+		runner.SessionID = ""
+		if fileCfg.SessionReuse {
+			prevSession := state.SessionID
+			sessionID, resetReason := harnessSessionFor(&state, task.TitleLine, runner.Model)
+			if resetReason != "" {
+				logHarnessSession(logFile, cfg.mode, iterNum, "reset", prevSession, resetReason)
+			}
+			runner.SessionID = sessionID
+		}
+		// Best-of-N: run parallel attempts in separate worktrees and continue
+		// with the selected one as if it were a single run.
+		var par *parallelRun
+		if cfg.mode == "build" && fileCfg.ParallelAttempts > 1 {
+			if blocker := parallelAttemptsBlocker(runner, gitAvailable, verifyCmds); blocker != "" {
+				fmt.Fprintf(os.Stderr, "Parallel attempts skipped: %s\n", blocker)
+			} else {
+				if !runner.Quiet {
+					fmt.Printf("Running %d parallel attempts...\n", fileCfg.ParallelAttempts)
+				}
+				run, runErr := runParallelAttempts(ctx, parallelRequest{
+					Count:          fileCfg.ParallelAttempts,
+					Policy:         normalizeParallelSelect(fileCfg.ParallelSelect),
+					HeadBefore:     headBefore,
+					Prompt:         promptContent,
+					Harness:        harness,
+					HarnessArgs:    effectiveHarnessArgs,
+					VerifyCmds:     verifyCmds,
+					AttemptTimeout: cfg.AttemptTimeout,
+					Retry:          retryCfg,
+					LogFile:        logFile,
+				}, fileCfg, state, runner)
+				par = &run
+				logParallelRun(logFile, cfg.mode, iterNum, run)
+				if !runner.Quiet {
+					printParallelRun(run)
+				}
+				state = run.Attempts[run.primary()].State
+				if runErr != nil {
+					run.Attempts[run.primary()].Err = runErr
+				}
+			}
+		}
+		var harnessRes harnessResult
+		var target harnessTarget
+		var fallbacks []harnessFallbackEvent
+		if par != nil {
+			harnessRes, target, fallbacks, err = par.result()
+		} else {
+			harnessRes, target, fallbacks, err = runHarnessChain(ctx, cfg.AttemptTimeout, promptContent, chain, fileCfg, &state, logFile, retryCfg, runner, cfg.mode, iterNum)
+		}
+		if fileCfg.SessionReuse && par == nil {
+			switch {
+			case err != nil && runner.SessionID != "" && ctx.Err() == nil:
+				// A stale session must not block the next run.
+				clearHarnessSession(&state)
+				logHarnessSession(logFile, cfg.mode, iterNum, "reset", runner.SessionID, sessionResetFailed)
+				if saveErr := saveState(state); saveErr != nil {
+					fmt.Fprintf(os.Stderr, "Warning: failed to save state: %v\n", saveErr)
+				}
+			case recordHarnessSession(&state, harnessRes, target, task.TitleLine, runner.Model):
+				logHarnessSession(logFile, cfg.mode, iterNum, "captured", harnessRes.SessionID, "")
+			case runner.SessionID != "" && harnessRes.SessionID == runner.SessionID:
+				logHarnessSession(logFile, cfg.mode, iterNum, "resumed", runner.SessionID, "")
+			}
+		}
 		iterStats.Attempts = harnessRes.RetryCount + 1 // retries + initial attempt
 		iterStats.Retries = harnessRes.RetryCount
+		iterStats.Fallbacks = fallbacks
+		if len(chain) > 1 {
+			iterStats.Harness = target.label()
+		}
+
+		// Token and cost accounting (counted even when the harness failed)
+		usage := harnessRes.Usage
+		if fileCfg.TokenUsage.enabled() {
+			if extracted, ok := extractTokenUsage(fileCfg.TokenUsage, harnessRes.RawOutput); ok {
+				usage = extracted
+			}
+		}
+		cost := estimateCost(fileCfg.Pricing, firstNonEmpty(target.Model, computeEffectiveModel(state, fileCfg)), usage)
+		iterStats.InputTokens = usage.InputTokens
+		iterStats.OutputTokens = usage.OutputTokens
+		iterStats.Cost = cost
+		recordUsage(report, task.TitleLine, usage, cost)
+		if par != nil {
+			for i, a := range par.Attempts {
+				if i == par.primary() {
+					continue
+				}
+				attemptTokens, attemptCost := attemptUsage(fileCfg, a)
+				iterStats.InputTokens += attemptTokens.InputTokens
+				iterStats.OutputTokens += attemptTokens.OutputTokens
+				iterStats.Cost += attemptCost
+				recordUsage(report, task.TitleLine, attemptTokens, attemptCost)
+			}
+			iterStats.ParallelAttempts = par.summaries(fileCfg)
+		}
+
+		if harnessRes.Adapter != "" && harnessRes.Adapter != "generic" {
+			iterStats.Adapter = harnessRes.Adapter
+			iterStats.ToolCalls = harnessRes.ToolCalls
+			iterStats.HarnessError = harnessRes.Errors
+		}
+		if (harnessRes.Adapter != "" && harnessRes.Adapter != "generic") || usage.total() > 0 {
+			writeLogEntry(logFile, logEntry{
+				Type:          "harness_result",
+				Mode:          cfg.mode,
+				Iteration:     iterNum,
+				Adapter:       harnessRes.Adapter,
+				ToolCalls:     harnessRes.ToolCalls,
+				InputTokens:   usage.InputTokens,
+				OutputTokens:  usage.OutputTokens,
+				Cost:          cost,
+				HarnessErrors: harnessRes.Errors,
+			})
+		}
 
 		if err != nil {
-			stop() // Clean up signal handler
+			stop() // Clean up iteration context
+			if ctx.Err() != nil {
+				writeLogEntry(logFile, logEntry{
+					Type:       "iteration_end",
+					Mode:       cfg.mode,
+					Iteration:  iterNum,
+					ExitReason: interruptExitReason,
+				})
+			}
 			if closeErr := logFile.Close(); closeErr != nil {
 				fmt.Fprintln(os.Stderr, closeErr)
 			}
 			if ctx.Err() != nil {
-				iterStats.ExitReason = "interrupted"
+				iterStats.ExitReason = interruptExitReason
 				iterStats.Duration = time.Since(startIter).String()
 				report.Iterations = append(report.Iterations, iterStats)
 				return iterationResult{}, fmt.Errorf("interrupted")
 			}
 			fmt.Fprintf(os.Stderr, "Harness failed: %v\n", err)
 			iterStats.ExitReason = "harness_failed"
+			if isHarnessIdleTimeout(err) {
+				iterStats.ExitReason = harnessIdleReason
+			}
 			iterStats.Duration = time.Since(startIter).String()
 			report.Iterations = append(report.Iterations, iterStats)
 			return iterationResult{}, fmt.Errorf("harness run failed: %w", err)
@@ -502,7 +702,32 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 		verifyStatus := "skipped"
 		verifyOutput := ""
 		if cfg.mode == "build" && len(verifyCmds) > 0 {
-			verifyOutput, err = runVerification(ctx, runner, verifyCmds, logFile)
+			if par != nil {
+				// Verify already ran in the selected attempt's worktree.
+				verifyOutput, err = par.verification()
+			} else {
+				verifyOutput, err = runVerification(ctx, runner, verifyCmds, logFile)
+			}
+			if ctx.Err() != nil {
+				// Aborted mid-verify: do not record a verify failure.
+				stop()
+				writeLogEntry(logFile, logEntry{
+					Type:       "iteration_end",
+					Mode:       cfg.mode,
+					Iteration:  iterNum,
+					ExitReason: interruptExitReason,
+				})
+				if closeErr := logFile.Close(); closeErr != nil {
+					fmt.Fprintln(os.Stderr, closeErr)
+				}
+				if err := saveState(state); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: failed to save state: %v\n", err)
+				}
+				iterStats.ExitReason = interruptExitReason
+				iterStats.Duration = time.Since(startIter).String()
+				report.Iterations = append(report.Iterations, iterStats)
+				return iterationResult{}, fmt.Errorf("interrupted")
+			}
 			if err != nil {
 				verifyStatus = "fail"
 			} else {
@@ -532,6 +757,7 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 			var err error
 			headAfter, err = gitOutput("rev-parse", "HEAD")
 			if err != nil {
+				stop()
 				iterStats.ExitReason = "git_error"
 				iterStats.Duration = time.Since(startIter).String()
 				report.Iterations = append(report.Iterations, iterStats)
@@ -550,11 +776,77 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 
 		guardrailOk := true
 		guardrailReason := ""
+		guardrailMessage := ""
 		worktreeChanged := false
+		depDelta := dependencyDelta{}
+		scopeDecl := scopeDeclaration{}
+		scopeRep := scopeReport{}
+		if cfg.mode == "build" {
+			scopeDecl = extractScopeDeclaration(output)
+		}
 		if cfg.mode == "build" {
 			if gitAvailable {
 				worktreeChanged = headAfter != headBefore || !isCleanWorkingTree() || planHashAfter != planHashBefore
 				guardrailOk, guardrailReason = enforceGuardrails(fileCfg, headBefore, headAfter)
+				if guardrailOk && largeFileGuardEnabled(fileCfg) {
+					found, gitErr := findLargeFilesGit(fileCfg, headBefore, headAfter)
+					if gitErr {
+						guardrailOk, guardrailReason = false, "git_error_file_list"
+					} else {
+						guardrailOk, guardrailReason, guardrailMessage = enforceLargeFileGuardrail(found)
+						reportLargeFiles(logFile, cfg.mode, iterNum, found, guardrailMessage)
+					}
+				}
+				var depErr error
+				depDelta, depErr = detectDependencyDelta(headBefore, headAfter)
+				if depErr != nil {
+					if guardrailOk && normalizeDependencyPolicy(fileCfg) != "allow" {
+						// Fail-closed: a restrictive policy cannot be checked without the file list
+						guardrailOk, guardrailReason = false, "git_error_file_list"
+					}
+				} else if !depDelta.empty() {
+					writeLogEntry(logFile, logEntry{
+						Type:         "dependency_delta",
+						Mode:         cfg.mode,
+						Iteration:    iterNum,
+						Manifests:    depDelta.Manifests,
+						Dependencies: depDelta.Changes,
+					})
+					for _, change := range depDelta.Changes {
+						fmt.Printf("Dependency change: %s\n", formatDependencyChange(change))
+					}
+					iterStats.Dependencies = depDelta.Changes
+					if guardrailOk {
+						guardrailOk, guardrailReason = enforceDependencyGuardrail(fileCfg, task, depDelta)
+					}
+				}
+				if guardrailOk {
+					var scopeMessage string
+					guardrailOk, guardrailReason, scopeMessage, scopeRep = checkScopeGuardrail(fileCfg, scopeDecl, headBefore, headAfter, []string{planPath})
+					if scopeMessage != "" {
+						fmt.Fprintf(os.Stderr, "Scope check:\n%s\n", scopeMessage)
+					}
+					if !guardrailOk {
+						guardrailMessage = scopeMessage
+					}
+				}
+				if guardrailOk {
+					var commitMessage string
+					guardrailOk, guardrailReason, commitMessage = enforceCommitPolicy(fileCfg.CommitPolicy, task, headBefore, headAfter)
+					if !guardrailOk {
+						guardrailMessage = commitMessage
+						writeLogEntry(logFile, logEntry{
+							Type:       "commit_policy",
+							Mode:       cfg.mode,
+							Iteration:  iterNum,
+							HeadBefore: headBefore,
+							HeadAfter:  headAfter,
+							Guardrail:  guardrailReason,
+							Message:    commitMessage,
+						})
+						fmt.Fprintf(os.Stderr, "Commit policy violation (%s):\n%s\n", guardrailReason, commitMessage)
+					}
+				}
 				if guardrailOk {
 					if missingVerify {
 						guardrailOk, guardrailReason = enforceMissingVerifyGuardrail(planPath, headBefore, headAfter, planHashBefore != planHashAfter)
@@ -562,16 +854,85 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 						guardrailOk, guardrailReason = enforceVerificationGuardrails(fileCfg, verifyStatus, planHashBefore != planHashAfter, worktreeChanged)
 					}
 				}
-			} else if missingVerify {
-				fingerprintAfterPlanExcluded := workspaceFingerprint(".", excludeDirs, []string{planPath})
-				guardrailOk, guardrailReason = enforceMissingVerifyNoGit(planHashBefore != planHashAfter, fingerprintBeforePlanExcluded, fingerprintAfterPlanExcluded)
+			} else {
+				if stampsBefore != nil {
+					found := findLargeFilesNoGit(fileCfg, ".", stampsBefore, workspaceStamps(".", excludeDirs))
+					guardrailOk, guardrailReason, guardrailMessage = enforceLargeFileGuardrail(found)
+					reportLargeFiles(logFile, cfg.mode, iterNum, found, guardrailMessage)
+				}
+				if guardrailOk && missingVerify {
+					fingerprintAfterPlanExcluded := workspaceFingerprint(".", excludeDirs, []string{planPath})
+					guardrailOk, guardrailReason = enforceMissingVerifyNoGit(planHashBefore != planHashAfter, fingerprintBeforePlanExcluded, fingerprintAfterPlanExcluded)
+				}
+			}
+		}
+
+		if cfg.mode == "build" && guardrailOk && len(fileCfg.GuardrailHooks) > 0 {
+			hookInput := guardrailHookInput{
+				Mode:         cfg.mode,
+				Iteration:    iterNum,
+				Task:         task.TitleLine,
+				VerifyStatus: verifyStatus,
+				HeadBefore:   headBefore,
+				HeadAfter:    headAfter,
+			}
+			if gitAvailable {
+				hookInput.ChangedFiles, _ = listChangedFiles(headBefore, headAfter)
+			}
+			if planHashBefore != planHashAfter {
+				hookInput.PlanDiff = generatePlanDiff(planPath, gitAvailable, 50)
+			}
+			hookResults := runGuardrailHooks(ctx, fileCfg.GuardrailHooks, fileCfg.GuardrailHookTimeout, hookInput)
+			for _, hr := range hookResults {
+				entry := logEntry{
+					Type:      "guardrail_hook",
+					Mode:      cfg.mode,
+					Iteration: iterNum,
+					Hook:      hr.Hook,
+					Severity:  hr.Verdict.Severity,
+					Message:   hr.message(),
+				}
+				if !hr.Verdict.OK || hr.Err != nil {
+					entry.Guardrail = hr.guardrailReason()
+				}
+				writeLogEntry(logFile, entry)
+				if !hr.blocking() && !hr.Verdict.OK {
+					fmt.Fprintf(os.Stderr, "Guardrail hook warning (%s): %s\n", hr.guardrailReason(), hr.message())
+				}
+			}
+			if blocked, ok := firstBlockingHook(hookResults); ok {
+				guardrailOk = false
+				guardrailReason = blocked.guardrailReason()
+				guardrailMessage = blocked.message()
+				fmt.Fprintf(os.Stderr, "Guardrail hook blocked iteration (%s): %s\n", guardrailReason, guardrailMessage)
 			}
 		}
 
 		pushAllowed := verifyStatus != "fail" && guardrailOk
+		if gitAvailable && !noPush && pushAllowed && headAfter != headBefore {
+			// A push publishes every unpushed commit, including ones a blocked
+			// earlier iteration left behind, so check all of them.
+			if ok, reason, message := enforceUnpushedCommitPolicy(fileCfg.CommitPolicy, unpushedBase(runStartHead), headBefore); !ok {
+				pushAllowed = false
+				guardrailOk = false
+				guardrailReason = reason
+				guardrailMessage = message
+				writeLogEntry(logFile, logEntry{
+					Type:       "commit_policy",
+					Mode:       cfg.mode,
+					Iteration:  iterNum,
+					HeadBefore: headBefore,
+					HeadAfter:  headAfter,
+					Guardrail:  reason,
+					Message:    message,
+				})
+				fmt.Fprintf(os.Stderr, "Commit policy violation in unpushed commits (%s):\n%s\n", reason, message)
+			}
+		}
 		if gitAvailable && !noPush && pushAllowed {
 			if headAfter != headBefore {
 				if err := gitPush(branch); err != nil {
+					stop()
 					iterStats.ExitReason = "git_push_failed"
 					iterStats.Duration = time.Since(startIter).String()
 					report.Iterations = append(report.Iterations, iterStats)
@@ -630,6 +991,13 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 			}
 		}
 
+		if exitReason == "" {
+			if budget, exceeded := budgetExceeded(fileCfg, report, task.TitleLine); exceeded {
+				fmt.Printf("Budget exceeded: %s\n", budget)
+				exitReason = "budget_exceeded"
+			}
+		}
+
 		// Persist backpressure state for next iteration
 		// Edge-triggered: only set backpressure if something failed THIS iteration
 		cleanIteration := guardrailOk &&
@@ -641,6 +1009,10 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 		// Archive resolved assumptions
 		if verifyStatus == "pass" {
 			state = archiveAssumptions(state, "verify", "verify_pass", iterNum, currentVerifyHash)
+			if state.SessionID != "" {
+				logHarnessSession(logFile, cfg.mode, iterNum, "reset", state.SessionID, sessionResetVerifyPass)
+				clearHarnessSession(&state)
+			}
 		}
 		if guardrailOk {
 			state = archiveAssumptions(state, "guardrail", "guardrail_pass", iterNum, "")
@@ -653,22 +1025,13 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 		var escalationEvent escalationEvent
 		state, escalationEvent = updateModelEscalationState(state, fileCfg)
 		if escalationEvent.Type != "none" {
-			writeLogEntry(logFile, logEntry{
-				Type:             "model_escalation",
-				FromModel:        escalationEvent.FromModel,
-				ToModel:          escalationEvent.ToModel,
-				EscalationReason: escalationEvent.Reason,
-				Escalated:        escalationEvent.Type == "escalated", // true if escalated
-				Cooldown:         escalationEvent.Cooldown,
-				// Note: de-escalation is also logged here with Escalated=false but Reason="min_strong_iterations_expired"
-				// or suppressed with reason="max_..."
-			})
+			writeLogEntry(logFile, escalationEvent.logEntry(state.EscalationCount))
 			if escalationEvent.Type == "escalated" {
-				fmt.Printf("Model escalation triggered: %s -> %s (reason: %s)\n",
-					escalationEvent.FromModel, escalationEvent.ToModel, escalationEvent.Reason)
+				fmt.Printf("Model escalation triggered: %s -> %s (tier %d -> %d, reason: %s)\n",
+					escalationEvent.FromModel, escalationEvent.ToModel, escalationEvent.FromTier, escalationEvent.ToTier, escalationEvent.Reason)
 			} else if escalationEvent.Type == "de_escalated" {
-				fmt.Printf("Model de-escalation: %s -> %s (reason: %s)\n",
-					escalationEvent.FromModel, escalationEvent.ToModel, escalationEvent.Reason)
+				fmt.Printf("Model de-escalation: %s -> %s (tier %d -> %d, reason: %s)\n",
+					escalationEvent.FromModel, escalationEvent.ToModel, escalationEvent.FromTier, escalationEvent.ToTier, escalationEvent.Reason)
 			}
 		}
 
@@ -676,6 +1039,7 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 			// Clear all backpressure fields after a clean iteration
 			state.PriorGuardrailStatus = ""
 			state.PriorGuardrailReason = ""
+			state.PriorGuardrailMessage = ""
 			state.PriorExitReason = ""
 			state.PriorRetryCount = 0
 			state.PriorRetryReason = ""
@@ -688,9 +1052,11 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 			if guardrailOk {
 				state.PriorGuardrailStatus = "pass"
 				state.PriorGuardrailReason = ""
+				state.PriorGuardrailMessage = ""
 			} else {
 				state.PriorGuardrailStatus = "fail"
 				state.PriorGuardrailReason = guardrailReason
+				state.PriorGuardrailMessage = guardrailMessage
 			}
 
 			state.PriorExitReason = exitReason
@@ -725,6 +1091,11 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 			fmt.Println("No changes detected in iteration.")
 		}
 
+		if exitReason == "" && interruptRequested(parentCtx) {
+			exitReason = interruptExitReason
+			fmt.Println("Interrupted; stopping after this iteration.")
+		}
+
 		writeLogEntry(logFile, logEntry{
 			Type:                "iteration_end",
 			Mode:                cfg.mode,
@@ -745,13 +1116,17 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 			Model:               state.CurrentModel,
 			Escalated:           escalated,
 			EscalationReason:    escalationReason,
+			ScopeWillChange:     scopeDecl.WillChange,
+			ScopeWillNotChange:  scopeDecl.WillNotChange,
+			ScopeUndeclared:     scopeRep.Undeclared,
+			ScopeExcluded:       scopeRep.Excluded,
 		})
 
 		if closeErr := logFile.Close(); closeErr != nil {
 			fmt.Fprintln(os.Stderr, closeErr)
 		}
 
-		// Clean up signal handler for this iteration
+		// Clean up the context for this iteration
 		stop()
 
 		iterResult := iterationResult{
@@ -770,6 +1145,7 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 		iterStats.VerifyStatus = iterResult.VerifyStatus
 		iterStats.Duration = time.Since(startIter).String()
 		report.Iterations = append(report.Iterations, iterStats)
+		runCheckpointFrom(parentCtx).recordIteration(iterResult, report)
 
 		if iterResult.ExitReason == "completion_contract_satisfied" {
 			state.CurrentModel = "" // Reset model usage on success? Or keep?
@@ -822,6 +1198,7 @@ var runMode = func(parentCtx context.Context, cfg modeConfig, fileCfg runtimeCon
 			Iteration: iterNum + 1, // For the *next* iteration
 			Mode:      cfg.mode,
 			Model:     state.CurrentModel,
+			Step:      cfg.step,
 		}
 
 		if parentCtx.Err() != nil {
@@ -877,6 +1254,16 @@ func hasCompletionSentinel(output string) bool {
 }
 
 func runVerification(ctx context.Context, runner runtimeExec, cmds []string, logFile *os.File) (string, error) {
+	return runVerificationTo(ctx, runner, cmds, os.Stdout, os.Stderr, logFile)
+}
+
+// runVerificationTo runs the verify commands, echoing progress and output to
+// stdout/stderr and copying the output to logFile when set.
+func runVerificationTo(ctx context.Context, runner runtimeExec, cmds []string, stdout, stderr io.Writer, logFile *os.File) (string, error) {
+	if logFile != nil {
+		stdout = io.MultiWriter(stdout, logFile)
+		stderr = io.MultiWriter(stderr, logFile)
+	}
 	var combined strings.Builder
 	for _, cmd := range cmds {
 		// Check for context cancellation before running each command
@@ -889,8 +1276,8 @@ func runVerification(ctx context.Context, runner runtimeExec, cmds []string, log
 		if cmd == "" {
 			continue
 		}
-		fmt.Printf("Running verification: %s\n", cmd)
-		output, err := runner.runShell(ctx, cmd, io.MultiWriter(os.Stdout, logFile), io.MultiWriter(os.Stderr, logFile))
+		fmt.Fprintf(stdout, "Running verification: %s\n", cmd)
+		output, err := runner.runShell(ctx, cmd, stdout, stderr)
 		if output != "" {
 			combined.WriteString("## Command: ")
 			combined.WriteString(cmd)
@@ -187,7 +187,7 @@ func TestShouldContinueUntil(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := shouldContinueUntil(tc.step, tc.result)
+			got := shouldContinueUntil(tc.step, strategyContext{Last: tc.result})
 			if got != tc.expected {
 				t.Errorf("shouldContinueUntil() = %v, want %v", got, tc.expected)
 			}
@@ -261,7 +261,7 @@ func TestPromptForMode(t *testing.T) {
 func TestShouldRunStep(t *testing.T) {
 	t.Run("no if condition", func(t *testing.T) {
 		step := strategyStep{Mode: "build"}
-		result := shouldRunStep(step, iterationResult{})
+		result := shouldRunStep(step, strategyContext{Last: iterationResult{}})
 		if !result {
 			t.Error("expected true when no if condition")
 		}
@@ -269,7 +269,7 @@ func TestShouldRunStep(t *testing.T) {
 
 	t.Run("if stalled true", func(t *testing.T) {
 		step := strategyStep{Mode: "build", If: "stalled"}
-		result := shouldRunStep(step, iterationResult{Stalled: true})
+		result := shouldRunStep(step, strategyContext{Last: iterationResult{Stalled: true}})
 		if !result {
 			t.Error("expected true when stalled matches")
 		}
@@ -277,7 +277,7 @@ func TestShouldRunStep(t *testing.T) {
 
 	t.Run("if stalled false", func(t *testing.T) {
 		step := strategyStep{Mode: "build", If: "stalled"}
-		result := shouldRunStep(step, iterationResult{Stalled: false})
+		result := shouldRunStep(step, strategyContext{Last: iterationResult{Stalled: false}})
 		if result {
 			t.Error("expected false when not stalled")
 		}
@@ -285,7 +285,7 @@ func TestShouldRunStep(t *testing.T) {
 
 	t.Run("if verify_fail matches", func(t *testing.T) {
 		step := strategyStep{Mode: "build", If: "verify_fail"}
-		result := shouldRunStep(step, iterationResult{VerifyStatus: "fail"})
+		result := shouldRunStep(step, strategyContext{Last: iterationResult{VerifyStatus: "fail"}})
 		if !result {
 			t.Error("expected true when verify failed")
 		}
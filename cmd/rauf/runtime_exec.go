@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 )
 
 var execCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
@@ -25,6 +26,32 @@ type runtimeExec struct {
 	DockerContainer string
 	WorkDir         string
 	Quiet           bool
+	Adapter         harnessAdapter
+	HTTP            httpHarnessConfig
+	// Mode, Iteration, Model and TaskID describe the current loop position
+	// for harness placeholders and the built-in replay harness. Attempt is
+	// the parallel attempt number (0 outside parallel attempts).
+	Mode      string
+	Iteration int
+	Model     string
+	TaskID    string
+	Attempt   int
+	// Stream, when set, parses the harness's stdout as it is written.
+	Stream *harnessStream
+	// IterationBase counts the iterations a strategy ran before the current
+	// step; IterationBase+Iteration is the iteration across the whole run.
+	IterationBase int
+	// SessionID resumes a previous harness conversation (session_reuse).
+	SessionID string
+	// IdleTimeout stops a harness that produces no output for this long.
+	IdleTimeout   time.Duration
+	IdleKillGrace time.Duration
+}
+
+// runIteration is the iteration across the whole run; strategy steps restart
+// Iteration at 1.
+func (r runtimeExec) runIteration() int {
+	return r.IterationBase + r.Iteration
 }
 
 func (r runtimeExec) isDocker() bool {
@@ -36,9 +63,30 @@ func (r runtimeExec) isDockerPersist() bool {
 	return value == "docker-persist" || value == "docker_persist"
 }
 
+// inProcessGroup starts cmd in its own process group and, for commands bound
+// to a context, terminates the whole group on cancel so grandchildren (test
+// runners, dev servers) do not outlive it.
+func inProcessGroup(cmd *exec.Cmd) *exec.Cmd {
+	setProcessGroup(cmd)
+	if cmd.Cancel != nil {
+		cmd.Cancel = func() error { return terminateProcessGroup(cmd, true) }
+	}
+	return cmd
+}
+
 func (r runtimeExec) command(ctx context.Context, name string, args ...string) (*exec.Cmd, error) {
+	cmd, err := r.baseCommand(ctx, name, args...)
+	if err != nil {
+		return nil, err
+	}
+	return inProcessGroup(cmd), nil
+}
+
+func (r runtimeExec) baseCommand(ctx context.Context, name string, args ...string) (*exec.Cmd, error) {
 	if !r.isDocker() && !r.isDockerPersist() {
-		return execCommand(ctx, name, args...), nil
+		cmd := execCommand(ctx, name, args...)
+		cmd.Dir = r.WorkDir
+		return cmd, nil
 	}
 	if r.DockerImage == "" {
 		return nil, errors.New("docker runtime requires docker_image")
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// scopeDeclaration holds the Phase 0d "Will change / Will NOT change" file lists
+// the agent declared in its output.
+type scopeDeclaration struct {
+	Found         bool
+	WillChange    []string
+	WillNotChange []string
+}
+
+// scopeReport lists changed files that disagree with the declaration.
+type scopeReport struct {
+	Undeclared []string
+	Excluded   []string
+}
+
+var (
+	scopeKeyLine   = regexp.MustCompile(`^\*{0,2}[A-Za-z][A-Za-z ()0-9'-]*:\*{0,2}`)
+	scopePathToken = regexp.MustCompile("[A-Za-z0-9_.*?/\\[\\]-]+")
+)
+
+// extractScopeDeclaration parses "Will change:" and "Will NOT change:" entries
+// outside code fences. Values may be inline (comma-separated) or a bulleted
+// list on the following lines. A later declaration replaces an earlier one.
+func extractScopeDeclaration(output string) scopeDeclaration {
+	decl := scopeDeclaration{}
+	var fence fenceState
+	var current *[]string
+	listIndent := -1
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence.processLine(trimmed) {
+			current = nil
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		item, isBullet := stripBullet(trimmed)
+		key, value, isKey := scopeHeader(item)
+		if isKey {
+			decl.Found = true
+			switch key {
+			case "will change":
+				decl.WillChange = scopePaths(value)
+				current = &decl.WillChange
+			case "will not change":
+				decl.WillNotChange = scopePaths(value)
+				current = &decl.WillNotChange
+			}
+			listIndent = -1
+			if isBullet {
+				listIndent = indent
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if !isBullet || indent < listIndent || (indent == listIndent && listIndent >= 0) || scopeKeyLine.MatchString(item) {
+			current = nil
+			continue
+		}
+		*current = append(*current, scopePaths(item)...)
+	}
+	return decl
+}
+
+func stripBullet(trimmed string) (string, bool) {
+	for _, marker := range []string{"- ", "* ", "+ "} {
+		if strings.HasPrefix(trimmed, marker) {
+			return strings.TrimSpace(trimmed[len(marker):]), true
+		}
+	}
+	return trimmed, false
+}
+
+// scopeHeader recognises a Phase 0d scope key, tolerating markdown bold.
+func scopeHeader(item string) (string, string, bool) {
+	plain := strings.ReplaceAll(item, "**", "")
+	idx := strings.Index(plain, ":")
+	if idx < 0 {
+		return "", "", false
+	}
+	key := strings.ToLower(strings.Join(strings.Fields(plain[:idx]), " "))
+	if key != "will change" && key != "will not change" {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(plain[idx+1:]), true
+}
+
+// scopePaths extracts path-like tokens from a declaration value.
+func scopePaths(value string) []string {
+	value = strings.ReplaceAll(value, "`", " ")
+	paths := []string{}
+	for _, token := range scopePathToken.FindAllString(value, -1) {
+		token = strings.TrimRight(token, ".")
+		token = strings.TrimPrefix(token, "./")
+		if token == "" || !strings.ContainsAny(token, "/.*") {
+			continue
+		}
+		paths = append(paths, token)
+	}
+	return paths
+}
+
+// scopeEntryMatches reports whether a repo-relative file falls under a declared entry.
+// Entries may be exact files, directories (with or without a trailing slash) or globs.
+func scopeEntryMatches(entry, file string) bool {
+	entry = strings.TrimPrefix(filepath.ToSlash(entry), "./")
+	file = strings.TrimPrefix(filepath.ToSlash(file), "./")
+	if entry == "" {
+		return false
+	}
+	if strings.ContainsAny(entry, "*?[") {
+		if ok, _ := path.Match(entry, file); ok {
+			return true
+		}
+		if !strings.Contains(entry, "/") {
+			ok, _ := path.Match(entry, path.Base(file))
+			return ok
+		}
+		return false
+	}
+	entry = strings.TrimSuffix(entry, "/")
+	return file == entry || strings.HasPrefix(file, entry+"/")
+}
+
+// compareScope checks changed files against a declaration. Files listed in
+// exempt (such as the plan file) are never reported.
+func compareScope(decl scopeDeclaration, files []string, exempt []string) scopeReport {
+	report := scopeReport{}
+	for _, file := range files {
+		if scopeExempt(file, exempt) {
+			continue
+		}
+		excluded := false
+		for _, entry := range decl.WillNotChange {
+			if scopeEntryMatches(entry, file) {
+				excluded = true
+				break
+			}
+		}
+		if excluded {
+			report.Excluded = append(report.Excluded, file)
+			continue
+		}
+		declared := false
+		for _, entry := range decl.WillChange {
+			if scopeEntryMatches(entry, file) {
+				declared = true
+				break
+			}
+		}
+		if !declared {
+			report.Undeclared = append(report.Undeclared, file)
+		}
+	}
+	return report
+}
+
+func scopeExempt(file string, exempt []string) bool {
+	for _, entry := range exempt {
+		if entry != "" && scopeEntryMatches(entry, file) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeScopePolicy(cfg runtimeConfig) string {
+	policy := strings.ToLower(strings.TrimSpace(cfg.ScopePolicy))
+	switch policy {
+	case "off", "warn", "enforce":
+		return policy
+	default:
+		return "warn"
+	}
+}
+
+// enforceScopeGuardrail compares the declared scope to the changed files.
+// It returns the guardrail outcome, a reason code, a human-readable
+// discrepancy list for backpressure, and the underlying report.
+func enforceScopeGuardrail(cfg runtimeConfig, decl scopeDeclaration, files []string, exempt []string) (bool, string, string, scopeReport) {
+	policy := normalizeScopePolicy(cfg)
+	if policy == "off" {
+		return true, "", "", scopeReport{}
+	}
+	report := compareScope(decl, files, exempt)
+	if !decl.Found {
+		if len(report.Undeclared) == 0 {
+			return true, "", "", report
+		}
+		message := "No Phase 0d scope declaration found. Changed files:\n" + formatScopeFiles(report.Undeclared)
+		if policy == "enforce" {
+			return false, "scope_declaration_missing", message, report
+		}
+		return true, "", message, report
+	}
+	if len(report.Excluded) == 0 && len(report.Undeclared) == 0 {
+		return true, "", "", report
+	}
+	var b strings.Builder
+	if len(report.Excluded) > 0 {
+		b.WriteString("Changed but declared \"Will NOT change\":\n")
+		b.WriteString(formatScopeFiles(report.Excluded))
+	}
+	if len(report.Undeclared) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("Changed but not declared in \"Will change\":\n")
+		b.WriteString(formatScopeFiles(report.Undeclared))
+	}
+	message := b.String()
+	if policy != "enforce" {
+		return true, "", message, report
+	}
+	if len(report.Excluded) > 0 {
+		return false, "scope_excluded_change", message, report
+	}
+	return false, "scope_undeclared_change", message, report
+}
+
+// checkScopeGuardrail lists the files changed between the two heads and
+// compares them to the declared scope. An enforced scope fails closed when
+// git cannot produce the file list.
+func checkScopeGuardrail(cfg runtimeConfig, decl scopeDeclaration, headBefore, headAfter string, exempt []string) (bool, string, string, scopeReport) {
+	files, gitErr := listChangedFiles(headBefore, headAfter)
+	if gitErr {
+		if normalizeScopePolicy(cfg) == "enforce" {
+			return false, "git_error_file_list", "", scopeReport{}
+		}
+		return true, "", "", scopeReport{}
+	}
+	return enforceScopeGuardrail(cfg, decl, files, exempt)
+}
+
+func formatScopeFiles(files []string) string {
+	lines := make([]string, 0, len(files))
+	for _, file := range files {
+		lines = append(lines, fmt.Sprintf("- %s", file))
+	}
+	return strings.Join(lines, "\n")
+}
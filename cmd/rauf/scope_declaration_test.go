@@ -0,0 +1,150 @@
+package main
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractScopeDeclarationInline(t *testing.T) {
+	output := "## Phase 0d — Architect Sanity Check\n\n" +
+		"- Task (1 sentence): add parser\n" +
+		"- Will change: `cmd/rauf/parser.go`, cmd/rauf/parser_test.go\n" +
+		"- **Will NOT change:** specs/, README.md\n" +
+		"- Existing code status: does not exist\n"
+	decl := extractScopeDeclaration(output)
+	if !decl.Found {
+		t.Fatalf("expected declaration to be found")
+	}
+	if !reflect.DeepEqual(decl.WillChange, []string{"cmd/rauf/parser.go", "cmd/rauf/parser_test.go"}) {
+		t.Fatalf("unexpected will change: %v", decl.WillChange)
+	}
+	if !reflect.DeepEqual(decl.WillNotChange, []string{"specs/", "README.md"}) {
+		t.Fatalf("unexpected will not change: %v", decl.WillNotChange)
+	}
+}
+
+func TestExtractScopeDeclarationNestedList(t *testing.T) {
+	output := "- Will change:\n" +
+		"  - cmd/rauf/a.go (add helper)\n" +
+		"  - cmd/rauf/*_test.go\n" +
+		"- Will NOT change:\n" +
+		"  - none\n" +
+		"- Existing code status: partial\n" +
+		"  - ignored/path.go\n"
+	decl := extractScopeDeclaration(output)
+	if !reflect.DeepEqual(decl.WillChange, []string{"cmd/rauf/a.go", "cmd/rauf/*_test.go"}) {
+		t.Fatalf("unexpected will change: %v", decl.WillChange)
+	}
+	if len(decl.WillNotChange) != 0 {
+		t.Fatalf("expected empty will not change, got %v", decl.WillNotChange)
+	}
+}
+
+func TestExtractScopeDeclarationIgnoresFences(t *testing.T) {
+	output := "```\n- Will change: fenced.go\n```\n"
+	if decl := extractScopeDeclaration(output); decl.Found {
+		t.Fatalf("expected fenced declaration to be ignored, got %+v", decl)
+	}
+
+	output = "```\n- Will change: fenced.go\n```\n- Will change: real.go\n"
+	decl := extractScopeDeclaration(output)
+	if !reflect.DeepEqual(decl.WillChange, []string{"real.go"}) {
+		t.Fatalf("unexpected will change: %v", decl.WillChange)
+	}
+}
+
+func TestScopeEntryMatches(t *testing.T) {
+	tests := []struct {
+		entry string
+		file  string
+		want  bool
+	}{
+		{"a.go", "a.go", true},
+		{"./a.go", "a.go", true},
+		{"cmd/", "cmd/rauf/a.go", true},
+		{"cmd", "cmd/rauf/a.go", true},
+		{"cmd", "cmdx/a.go", false},
+		{"cmd/rauf/*_test.go", "cmd/rauf/a_test.go", true},
+		{"*.md", "docs/guide.md", true},
+		{"docs/*.md", "specs/guide.md", false},
+	}
+	for _, tc := range tests {
+		if got := scopeEntryMatches(tc.entry, tc.file); got != tc.want {
+			t.Errorf("scopeEntryMatches(%q, %q) = %t, want %t", tc.entry, tc.file, got, tc.want)
+		}
+	}
+}
+
+func TestEnforceScopeGuardrail(t *testing.T) {
+	decl := scopeDeclaration{
+		Found:         true,
+		WillChange:    []string{"cmd/"},
+		WillNotChange: []string{"cmd/rauf/main.go"},
+	}
+	exempt := []string{"IMPLEMENTATION_PLAN.md"}
+	enforce := runtimeConfig{ScopePolicy: "enforce"}
+
+	ok, reason, _, _ := enforceScopeGuardrail(enforce, decl, []string{"cmd/rauf/a.go", "IMPLEMENTATION_PLAN.md"}, exempt)
+	if !ok {
+		t.Fatalf("expected declared change to pass, got %s", reason)
+	}
+
+	ok, reason, message, report := enforceScopeGuardrail(enforce, decl, []string{"cmd/rauf/main.go", "README.md"}, exempt)
+	if ok || reason != "scope_excluded_change" {
+		t.Fatalf("expected scope_excluded_change, got ok=%t reason=%s", ok, reason)
+	}
+	if !reflect.DeepEqual(report.Excluded, []string{"cmd/rauf/main.go"}) || !reflect.DeepEqual(report.Undeclared, []string{"README.md"}) {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if !strings.Contains(message, "- README.md") || !strings.Contains(message, "- cmd/rauf/main.go") {
+		t.Fatalf("expected discrepancy list in message, got %q", message)
+	}
+
+	ok, reason, _, _ = enforceScopeGuardrail(enforce, decl, []string{"README.md"}, exempt)
+	if ok || reason != "scope_undeclared_change" {
+		t.Fatalf("expected scope_undeclared_change, got ok=%t reason=%s", ok, reason)
+	}
+
+	ok, reason, _, _ = enforceScopeGuardrail(enforce, scopeDeclaration{}, []string{"README.md"}, exempt)
+	if ok || reason != "scope_declaration_missing" {
+		t.Fatalf("expected scope_declaration_missing, got ok=%t reason=%s", ok, reason)
+	}
+
+	ok, _, message, _ = enforceScopeGuardrail(runtimeConfig{ScopePolicy: "warn"}, decl, []string{"README.md"}, exempt)
+	if !ok || message == "" {
+		t.Fatalf("expected warn policy to pass with a message, got ok=%t message=%q", ok, message)
+	}
+
+	ok, _, message, _ = enforceScopeGuardrail(runtimeConfig{ScopePolicy: "off"}, decl, []string{"README.md"}, exempt)
+	if !ok || message != "" {
+		t.Fatalf("expected off policy to skip checks, got ok=%t message=%q", ok, message)
+	}
+}
+
+func TestCheckScopeGuardrailGitError(t *testing.T) {
+	origGitExec := gitExec
+	defer func() { gitExec = origGitExec }()
+	gitExec = func(args ...string) (string, error) {
+		return "", exec.ErrNotFound
+	}
+	decl := scopeDeclaration{Found: true, WillChange: []string{"cmd/"}}
+
+	ok, reason, _, _ := checkScopeGuardrail(runtimeConfig{ScopePolicy: "enforce"}, decl, "abc", "def", nil)
+	if ok || reason != "git_error_file_list" {
+		t.Fatalf("expected enforce to fail closed, got ok=%t reason=%s", ok, reason)
+	}
+	ok, reason, _, _ = checkScopeGuardrail(runtimeConfig{ScopePolicy: "warn"}, decl, "abc", "def", nil)
+	if !ok || reason != "" {
+		t.Fatalf("expected warn to skip the check, got ok=%t reason=%s", ok, reason)
+	}
+}
+
+func TestEmbeddedBuildPromptDeclaresScope(t *testing.T) {
+	for _, want := range []string{"## Phase 0d — Architect Sanity Check", "- Will change:", "- Will NOT change:"} {
+		if !strings.Contains(promptBuild, want) {
+			t.Fatalf("expected embedded build prompt to contain %q", want)
+		}
+	}
+}
@@ -17,6 +17,7 @@ type raufState struct {
 	LastVerificationHash    string `json:"last_verification_hash"`
 	PriorGuardrailStatus    string `json:"prior_guardrail_status"`
 	PriorGuardrailReason    string `json:"prior_guardrail_reason"`
+	PriorGuardrailMessage   string `json:"prior_guardrail_message,omitempty"`
 	PriorExitReason         string `json:"prior_exit_reason"`
 	PlanHashBefore          string `json:"plan_hash_before"`
 	PlanHashAfter           string `json:"plan_hash_after"`
@@ -33,6 +34,14 @@ type raufState struct {
 	NoProgressStreak             int    `json:"no_progress_streak,omitempty"`
 	LastEscalationReason         string `json:"last_escalation_reason,omitempty"`
 	RecoveryMode                 string `json:"recovery_mode,omitempty"`
+	// Harness session reuse
+	SessionID      string `json:"session_id,omitempty"`
+	SessionTask    string `json:"session_task,omitempty"`
+	SessionHarness string `json:"session_harness,omitempty"`
+	SessionModel   string `json:"session_model,omitempty"`
+	// Harness fallback state (index 0 is the primary harness)
+	HarnessFallbackIndex    int `json:"harness_fallback_index,omitempty"`
+	HarnessFallbackCooldown int `json:"harness_fallback_cooldown,omitempty"`
 	// Hypothesis tracking
 	Hypotheses []Hypothesis `json:"hypotheses,omitempty"`
 	// Assumption tracking
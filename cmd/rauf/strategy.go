@@ -3,48 +3,210 @@ package main
 import (
 	"fmt"
 	"os"
-	"strings"
 )
 
 type strategyStep struct {
+	ID         string
 	Mode       string
 	Iterations int
 	Until      string
 	If         string
+	Goto       string
+	Repeat     int
+	Overrides  strategyOverrides
 }
 
-func shouldRunStep(step strategyStep, lastResult iterationResult) bool {
+// defaultStrategyMaxIterations caps the iterations of one strategy run when
+// strategy_max_iterations is not set.
+const defaultStrategyMaxIterations = 100
+
+// strategyIterationCapReason is the exit reason when a strategy run hits
+// strategy_max_iterations.
+const strategyIterationCapReason = "strategy_iteration_cap"
+
+// strategyCounters accumulate over a whole strategy run.
+type strategyCounters struct {
+	Iterations  int `json:"iterations"`
+	Escalations int `json:"escalations"`
+	VerifyFails int `json:"verify_fails"`
+	// Jumps counts goto jumps taken per step index, bounded by `repeat`.
+	Jumps map[int]int `json:"jumps,omitempty"`
+	// SpecsHash fingerprints specs/ at the start of the run and after each
+	// plan step, for the `specs_changed` condition.
+	SpecsHash string `json:"specs_hash,omitempty"`
+}
+
+// strategyContext is what `if:` and `until:` conditions are evaluated
+// against: the last iteration's result, the state as the previous iteration
+// left it, and counters accumulated across steps.
+type strategyContext struct {
+	Last     iterationResult
+	State    raufState
+	PlanPath string
+	strategyCounters
+}
+
+// observe folds one finished iteration into the context.
+func (sc *strategyContext) observe(result iterationResult, state raufState) {
+	sc.Iterations++
+	if result.VerifyStatus == "fail" {
+		sc.VerifyFails++
+	}
+	if state.EscalationCount > sc.State.EscalationCount {
+		sc.Escalations += state.EscalationCount - sc.State.EscalationCount
+	}
+	sc.Last = result
+	sc.State = state
+}
+
+// counter returns a named counter for `name >= N` style conditions.
+func (sc strategyContext) counter(name string) (int, bool) {
+	switch name {
+	case "consecutive_verify_fails":
+		return sc.State.ConsecutiveVerifyFails, true
+	case "consecutive_guardrail_fails":
+		return sc.State.ConsecutiveGuardrailFails, true
+	case "no_progress_streak":
+		return sc.State.NoProgressStreak, true
+	case "escalation_count":
+		return sc.State.EscalationCount, true
+	case "escalations":
+		return sc.Escalations, true
+	case "iterations":
+		return sc.Iterations, true
+	case "verify_fails":
+		return sc.VerifyFails, true
+	case "no_progress":
+		return sc.Last.NoProgress, true
+	}
+	return 0, false
+}
+
+// specsFingerprint hashes specs/ for the `specs_changed` condition.
+func specsFingerprint() string {
+	return workspaceFingerprint("specs", nil, nil)
+}
+
+func shouldRunStep(step strategyStep, sc strategyContext) bool {
 	if step.If == "" {
 		return true
 	}
-	switch strings.ToLower(step.If) {
-	case "stalled":
-		return lastResult.Stalled
-	case "verify_fail":
-		return lastResult.VerifyStatus == "fail"
-	case "verify_pass":
-		return lastResult.VerifyStatus == "pass"
-	default:
-		fmt.Fprintf(os.Stderr, "Warning: unknown strategy 'if' condition %q, defaulting to true\n", step.If)
+	cond, err := parseStrategyCondition(step.If)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: invalid strategy 'if' condition %q (%v), defaulting to true\n", step.If, err)
 		return true
 	}
+	return cond(sc)
 }
 
-func shouldContinueUntil(step strategyStep, result iterationResult) bool {
+func shouldContinueUntil(step strategyStep, sc strategyContext) bool {
 	if step.Until == "" {
 		// No "until" condition means continue up to max iterations
 		return true
 	}
-	switch strings.ToLower(step.Until) {
-	case "verify_pass":
-		// Continue until verification passes
-		return result.VerifyStatus != "pass"
-	case "verify_fail":
-		// Continue until verification fails
-		return result.VerifyStatus != "fail"
-	default:
-		// Unknown condition: warn and continue up to max iterations
-		fmt.Fprintf(os.Stderr, "Warning: unknown strategy 'until' condition %q, continuing to max iterations\n", step.Until)
+	cond, err := parseStrategyCondition(step.Until)
+	if err != nil {
+		// Invalid condition: warn and continue up to max iterations
+		fmt.Fprintf(os.Stderr, "Warning: invalid strategy 'until' condition %q (%v), continuing to max iterations\n", step.Until, err)
 		return true
 	}
+	return !cond(sc)
+}
+
+// strategyStepIndex maps step ids to their index.
+func strategyStepIndex(steps []strategyStep) map[string]int {
+	index := make(map[string]int, len(steps))
+	for i, step := range steps {
+		if step.ID != "" {
+			index[step.ID] = i
+		}
+	}
+	return index
+}
+
+// validateStrategy rejects strategies that cannot run: duplicate ids, unknown goto targets and
+// unbounded loops. A goto back to the same or an earlier step needs a positive `repeat`.
+// Invalid conditions are not rejected; they warn and fall back to a default when evaluated.
+func validateStrategy(steps []strategyStep) error {
+	seen := map[string]bool{}
+	for i, step := range steps {
+		if step.ID == "" {
+			continue
+		}
+		if seen[step.ID] {
+			return fmt.Errorf("strategy step %d: duplicate id %q", i+1, step.ID)
+		}
+		seen[step.ID] = true
+	}
+	index := strategyStepIndex(steps)
+	for i, step := range steps {
+		if step.Goto == "" && step.Repeat == 0 {
+			continue
+		}
+		target, ok := strategyGotoTarget(step, i, index)
+		if !ok {
+			return fmt.Errorf("strategy step %d: goto target %q not found", i+1, step.Goto)
+		}
+		if target <= i && step.Repeat <= 0 {
+			return fmt.Errorf("strategy step %d: goto %q loops back without a repeat limit", i+1, step.Goto)
+		}
+	}
+	return nil
+}
+
+// strategyConditionWarnings lists the `if:` and `until:` conditions that do
+// not parse.
+func strategyConditionWarnings(steps []strategyStep) []string {
+	var warnings []string
+	for i, step := range steps {
+		for _, expr := range []string{step.If, step.Until} {
+			if expr == "" {
+				continue
+			}
+			if _, err := parseStrategyCondition(expr); err != nil {
+				warnings = append(warnings, fmt.Sprintf("strategy step %d: %v", i+1, err))
+			}
+		}
+	}
+	return warnings
+}
+
+// strategyGotoTarget resolves a step's goto; `repeat` without `goto` repeats
+// the step itself.
+func strategyGotoTarget(step strategyStep, idx int, index map[string]int) (int, bool) {
+	if step.Goto == "" {
+		return idx, true
+	}
+	target, ok := index[step.Goto]
+	return target, ok
+}
+
+// nextStrategyStep returns the step to run after step idx, following its
+// goto while the repeat budget lasts.
+func nextStrategyStep(steps []strategyStep, idx int, index map[string]int, sc *strategyContext) int {
+	step := steps[idx]
+	if step.Goto == "" && step.Repeat == 0 {
+		return idx + 1
+	}
+	target, ok := strategyGotoTarget(step, idx, index)
+	if !ok {
+		return idx + 1
+	}
+	if step.Repeat > 0 && sc.Jumps[idx] >= step.Repeat {
+		return idx + 1
+	}
+	if sc.Jumps == nil {
+		sc.Jumps = map[int]int{}
+	}
+	sc.Jumps[idx]++
+	return target
+}
+
+// reloadState reads the state a runMode call saved, so the next strategy
+// step sees it. The previous state is kept when nothing was saved.
+func reloadState(previous raufState) raufState {
+	if _, err := os.Stat(statePath()); err != nil {
+		return previous
+	}
+	return loadState()
 }
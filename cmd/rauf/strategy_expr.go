@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// strategyCondition is a compiled `if:`/`until:` expression.
+type strategyCondition func(sc strategyContext) bool
+
+// strategyExitConditions are exit reasons usable as bare conditions; they
+// hold when the last iteration ended with that reason.
+var strategyExitConditions = map[string]bool{
+	"no_unchecked_tasks":            true,
+	"no_progress":                   true,
+	"completion_contract_satisfied": true,
+	"max_iterations_reached":        true,
+}
+
+// strategyAtom evaluates a bare condition name.
+func strategyAtom(name string) (strategyCondition, bool) {
+	switch name {
+	case "true", "always":
+		return func(strategyContext) bool { return true }, true
+	case "stalled":
+		return func(sc strategyContext) bool { return sc.Last.Stalled }, true
+	case "verify_pass":
+		return func(sc strategyContext) bool { return sc.Last.VerifyStatus == "pass" }, true
+	case "verify_fail":
+		return func(sc strategyContext) bool { return sc.Last.VerifyStatus == "fail" }, true
+	case "escalated":
+		return func(sc strategyContext) bool { return sc.Escalations > 0 }, true
+	case "has_unchecked_tasks":
+		return func(sc strategyContext) bool { return hasUncheckedTasks(sc.PlanPath) }, true
+	case "specs_changed":
+		return func(sc strategyContext) bool { return specsFingerprint() != sc.SpecsHash }, true
+	}
+	if strategyExitConditions[name] {
+		return func(sc strategyContext) bool { return sc.Last.ExitReason == name }, true
+	}
+	return nil, false
+}
+
+// parseStrategyCondition compiles a condition such as
+// `verify_fail and (consecutive_verify_fails >= 3 or not escalated)`.
+// Operators: and/or/not (also &&, ||, !) and counter comparisons.
+func parseStrategyCondition(expr string) (strategyCondition, error) {
+	tokens, err := tokenizeStrategyCondition(strings.ToLower(expr))
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty condition")
+	}
+	p := &conditionParser{tokens: tokens}
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in condition %q", p.tokens[p.pos], expr)
+	}
+	return cond, nil
+}
+
+func tokenizeStrategyCondition(expr string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case strings.HasPrefix(expr[i:], "&&"), strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, expr[i:i+2])
+			i += 2
+		case strings.ContainsRune("<>=!", c):
+			j := i + 1
+			if j < len(expr) && expr[j] == '=' {
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		case c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c):
+			j := i
+			for j < len(expr) && (expr[j] == '_' || unicode.IsLetter(rune(expr[j])) || unicode.IsDigit(rune(expr[j]))) {
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q in condition", c)
+		}
+	}
+	return tokens, nil
+}
+
+type conditionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *conditionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *conditionParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *conditionParser) parseOr() (strategyCondition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "or" || p.peek() == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(sc strategyContext) bool { return l(sc) || right(sc) }
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (strategyCondition, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "and" || p.peek() == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(sc strategyContext) bool { return l(sc) && right(sc) }
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (strategyCondition, error) {
+	switch tok := p.next(); tok {
+	case "not", "!":
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(sc strategyContext) bool { return !inner(sc) }, nil
+	case "(":
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		return inner, nil
+	case "":
+		return nil, fmt.Errorf("condition ends unexpectedly")
+	default:
+		return p.parseAtom(tok)
+	}
+}
+
+func (p *conditionParser) parseAtom(name string) (strategyCondition, error) {
+	switch op := p.peek(); op {
+	case ">=", "<=", "==", "!=", ">", "<":
+		p.next()
+		want, err := strconv.Atoi(p.next())
+		if err != nil {
+			return nil, fmt.Errorf("%s %s needs an integer", name, op)
+		}
+		if _, ok := (strategyContext{}).counter(name); !ok {
+			return nil, fmt.Errorf("unknown counter %q", name)
+		}
+		return func(sc strategyContext) bool {
+			got, _ := sc.counter(name)
+			return compareCounter(got, op, want)
+		}, nil
+	}
+	if cond, ok := strategyAtom(name); ok {
+		return cond, nil
+	}
+	return nil, fmt.Errorf("unknown condition %q", name)
+}
+
+func compareCounter(got int, op string, want int) bool {
+	switch op {
+	case ">=":
+		return got >= want
+	case "<=":
+		return got <= want
+	case "==":
+		return got == want
+	case "!=":
+		return got != want
+	case ">":
+		return got > want
+	case "<":
+		return got < want
+	}
+	return false
+}
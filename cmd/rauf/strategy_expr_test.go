@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseStrategyCondition(t *testing.T) {
+	sc := strategyContext{
+		Last:  iterationResult{ExitReason: "no_progress", NoProgress: 2, VerifyStatus: "fail"},
+		State: raufState{ConsecutiveVerifyFails: 3},
+	}
+	sc.Iterations = 4
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"no_progress", true},
+		{"no_progress >= 2", true},
+		{"no_unchecked_tasks", false},
+		{"verify_fail and consecutive_verify_fails >= 3", true},
+		{"verify_pass or iterations == 4", true},
+		{"not escalated", true},
+		{"!(verify_fail && iterations > 10)", true},
+		{"stalled or (no_progress and not verify_pass)", true},
+		{"completion_contract_satisfied || iterations < 2", false},
+		{"NOT Stalled", true},
+	}
+	for _, tc := range tests {
+		cond, err := parseStrategyCondition(tc.expr)
+		if err != nil {
+			t.Errorf("parse %q: %v", tc.expr, err)
+			continue
+		}
+		if got := cond(sc); got != tc.want {
+			t.Errorf("%q = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+
+	for _, expr := range []string{"", "bogus", "iterations >= x", "stalled and", "(stalled", "stalled )", "unknown_counter > 1", "stalled % 2"} {
+		if _, err := parseStrategyCondition(expr); err == nil {
+			t.Errorf("expected %q to be rejected", expr)
+		}
+	}
+}
+
+func TestStrategyPlanConditions(t *testing.T) {
+	dir := t.TempDir()
+	chdirTemp(t, dir)
+	plan := filepath.Join(dir, "IMPLEMENTATION_PLAN.md")
+	if err := os.WriteFile(plan, []byte("- [ ] T1: todo\n"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	sc := strategyContext{PlanPath: plan}
+	sc.SpecsHash = specsFingerprint()
+
+	cond, err := parseStrategyCondition("has_unchecked_tasks and not specs_changed")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if !cond(sc) {
+		t.Fatalf("expected unchecked tasks and unchanged specs")
+	}
+	if err := os.MkdirAll("specs", 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join("specs", "new.md"), []byte("# spec\n"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if cond(sc) {
+		t.Fatalf("expected specs_changed after adding a spec")
+	}
+}
+
+func TestValidateStrategy(t *testing.T) {
+	tests := []struct {
+		steps []strategyStep
+		err   string
+	}{
+		{[]strategyStep{{ID: "a", Mode: "build"}, {ID: "a", Mode: "plan"}}, "duplicate id"},
+		{[]strategyStep{{Mode: "build", Goto: "missing"}}, "not found"},
+		{[]strategyStep{{ID: "a", Mode: "build"}, {Mode: "plan", Goto: "a"}}, "without a repeat limit"},
+		{[]strategyStep{{Mode: "build", If: "stalled or"}}, ""},
+		{[]strategyStep{{ID: "a", Mode: "build"}, {Mode: "plan", Goto: "a", Repeat: 3}}, ""},
+		{[]strategyStep{{Mode: "plan", Goto: "b"}, {Mode: "plan"}, {ID: "b", Mode: "build"}}, ""},
+	}
+	for i, tc := range tests {
+		err := validateStrategy(tc.steps)
+		if tc.err == "" && err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+		}
+		if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("case %d: expected %q, got %v", i, tc.err, err)
+		}
+	}
+}
+
+func TestStrategyConditionWarnings(t *testing.T) {
+	steps := []strategyStep{
+		{Mode: "build", If: "stalled or"},
+		{Mode: "plan", Until: "verify_pass"},
+		{Mode: "build", Until: "not_a_condition"},
+	}
+	warnings := strategyConditionWarnings(steps)
+	if len(warnings) != 2 || !strings.HasPrefix(warnings[0], "strategy step 1:") || !strings.HasPrefix(warnings[1], "strategy step 3:") {
+		t.Fatalf("unexpected warnings: %q", warnings)
+	}
+	// Previously accepted strategies with unknown conditions still run.
+	if err := validateStrategy(steps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !shouldRunStep(steps[0], strategyContext{}) || !shouldContinueUntil(steps[2], strategyContext{}) {
+		t.Fatalf("expected invalid conditions to fall back to their defaults")
+	}
+}
+
+func TestRunStrategyGotoRepeat(t *testing.T) {
+	chdirTemp(t, t.TempDir())
+
+	origRunMode := runMode
+	defer func() { runMode = origRunMode }()
+	var modes []string
+	runMode = func(ctx context.Context, cfg modeConfig, fileCfg runtimeConfig, runner runtimeExec, state raufState, gitAvailable bool, branch, planPath, harness, harnessArgs string, noPush bool, logDir string, retryEnabled bool, retryMaxAttempts int, retryBackoffBase, retryBackoffMax time.Duration, retryJitter bool, retryMatch []string, startNoProgress int, stdin io.Reader, stdout io.Writer, report *RunReport) (iterationResult, error) {
+		modes = append(modes, cfg.mode)
+		if cfg.mode == "build" {
+			return iterationResult{ExitReason: "no_progress", Stalled: true}, nil
+		}
+		return iterationResult{}, nil
+	}
+
+	// Build until stalled, re-plan, build again: at most 2 extra cycles.
+	fileCfg := runtimeConfig{Strategy: []strategyStep{
+		{ID: "build", Mode: "build", Iterations: 5},
+		{Mode: "plan", If: "no_progress", Goto: "build", Repeat: 2},
+	}}
+	report := &RunReport{}
+	if err := runStrategy(context.Background(), modeConfig{}, fileCfg, runtimeExec{}, raufState{}, false, "", "", "", "", true, "logs", false, 0, 0, 0, false, nil, nil, nil, report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(modes, ","); got != "build,plan,build,plan,build,plan" {
+		t.Fatalf("unexpected step order: %s", got)
+	}
+
+	modes = nil
+	fileCfg.StrategyMaxIterations = 3
+	if err := runStrategy(context.Background(), modeConfig{}, fileCfg, runtimeExec{}, raufState{}, false, "", "", "", "", true, "logs", false, 0, 0, 0, false, nil, nil, nil, report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(modes) != 3 {
+		t.Fatalf("expected the cap to stop after 3 iterations, got %v", modes)
+	}
+	last := report.Iterations[len(report.Iterations)-1]
+	if last.ExitReason != strategyIterationCapReason {
+		t.Fatalf("expected cap exit reason, got %+v", last)
+	}
+
+	fileCfg.Strategy[1].Repeat = 0
+	if err := runStrategy(context.Background(), modeConfig{}, fileCfg, runtimeExec{}, raufState{}, false, "", "", "", "", true, "logs", false, 0, 0, 0, false, nil, nil, nil, report); err == nil {
+		t.Fatalf("expected an unbounded loop to be rejected")
+	}
+}
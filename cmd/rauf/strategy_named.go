@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// selectStrategy picks the steps to run: the named strategy when name is
+// set, else default_strategy, else the unnamed `strategy:` list. An empty
+// step list means no strategy applies.
+func selectStrategy(cfg runtimeConfig, name string) (string, []strategyStep, error) {
+	if name == "" {
+		name = cfg.DefaultStrategy
+	}
+	if name == "" {
+		return "", cfg.Strategy, nil
+	}
+	steps, ok := cfg.Strategies[name]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown strategy %q (available: %s)", name, strings.Join(strategyNames(cfg), ", "))
+	}
+	if len(steps) == 0 {
+		return "", nil, fmt.Errorf("strategy %q has no steps", name)
+	}
+	return name, steps, nil
+}
+
+func strategyNames(cfg runtimeConfig) []string {
+	names := make([]string, 0, len(cfg.Strategies))
+	for name := range cfg.Strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// printStrategies lists each strategy with its steps for `rauf run --list`.
+func printStrategies(w io.Writer, cfg runtimeConfig) {
+	if len(cfg.Strategy) == 0 && len(cfg.Strategies) == 0 {
+		fmt.Fprintln(w, "No strategies configured.")
+		return
+	}
+	if len(cfg.Strategy) > 0 {
+		label := "strategy"
+		if cfg.DefaultStrategy == "" {
+			label += " (default)"
+		}
+		printStrategy(w, label, cfg.Strategy)
+	}
+	for _, name := range strategyNames(cfg) {
+		label := name
+		if name == cfg.DefaultStrategy {
+			label += " (default)"
+		}
+		printStrategy(w, label, cfg.Strategies[name])
+	}
+}
+
+func printStrategy(w io.Writer, label string, steps []strategyStep) {
+	fmt.Fprintf(w, "%s:\n", label)
+	for i, step := range steps {
+		fmt.Fprintf(w, "  %d. %s\n", i+1, describeStrategyStep(step))
+	}
+	if err := validateStrategy(steps); err != nil {
+		fmt.Fprintf(w, "  invalid: %v\n", err)
+	}
+	for _, warning := range strategyConditionWarnings(steps) {
+		fmt.Fprintf(w, "  warning: %s\n", warning)
+	}
+}
+
+// describeStrategyStep renders a step on one line: mode, id, iterations,
+// conditions, jumps and overridden settings.
+func describeStrategyStep(step strategyStep) string {
+	parts := []string{step.Mode}
+	if step.ID != "" {
+		parts[0] += " [" + step.ID + "]"
+	}
+	iterations := step.Iterations
+	if iterations <= 0 {
+		iterations = 1
+	}
+	parts = append(parts, fmt.Sprintf("iterations: %d", iterations))
+	if step.If != "" {
+		parts = append(parts, "if: "+step.If)
+	}
+	if step.Until != "" {
+		parts = append(parts, "until: "+step.Until)
+	}
+	if step.Goto != "" || step.Repeat > 0 {
+		target := step.Goto
+		if target == "" {
+			target = "self"
+		}
+		jump := "goto: " + target
+		if step.Repeat > 0 {
+			jump += fmt.Sprintf(" (repeat %d)", step.Repeat)
+		}
+		parts = append(parts, jump)
+	}
+	if overrides := step.Overrides.describe(); overrides != "" {
+		parts = append(parts, "overrides: "+overrides)
+	}
+	return strings.Join(parts, ", ")
+}
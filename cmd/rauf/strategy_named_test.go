@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const namedStrategiesConfig = `default_strategy: nightly
+strategies:
+  nightly:
+    - id: build
+      mode: build
+      iterations: 10
+      until: stalled or no_unchecked_tasks
+    - mode: plan
+      if: stalled and has_unchecked_tasks
+      goto: build
+      repeat: 3
+  bugfix:
+  - mode: build
+    model: strong
+    harness_args: ""
+strategy:
+  - mode: plan
+`
+
+func TestParseConfigNamedStrategies(t *testing.T) {
+	cfg := runtimeConfig{}
+	if err := parseConfigBytes([]byte(namedStrategiesConfig), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if cfg.DefaultStrategy != "nightly" || len(cfg.Strategies) != 2 || len(cfg.Strategy) != 1 {
+		t.Fatalf("unexpected strategies: %+v", cfg.Strategies)
+	}
+	nightly := cfg.Strategies["nightly"]
+	if len(nightly) != 2 || nightly[0].ID != "build" || nightly[0].Iterations != 10 || nightly[1].Goto != "build" || nightly[1].Repeat != 3 {
+		t.Fatalf("unexpected nightly steps: %+v", nightly)
+	}
+	bugfix := cfg.Strategies["bugfix"]
+	if len(bugfix) != 1 || bugfix[0].Overrides.Model != "strong" || bugfix[0].Overrides.HarnessArgs == nil {
+		t.Fatalf("unexpected bugfix steps: %+v", bugfix)
+	}
+}
+
+func TestSelectStrategy(t *testing.T) {
+	cfg := runtimeConfig{}
+	if err := parseConfigBytes([]byte(namedStrategiesConfig), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if name, steps, err := selectStrategy(cfg, ""); err != nil || name != "nightly" || len(steps) != 2 {
+		t.Fatalf("expected default strategy, got %q %d %v", name, len(steps), err)
+	}
+	if name, steps, err := selectStrategy(cfg, "bugfix"); err != nil || name != "bugfix" || len(steps) != 1 {
+		t.Fatalf("expected bugfix, got %q %d %v", name, len(steps), err)
+	}
+	if _, _, err := selectStrategy(cfg, "weekly"); err == nil || !strings.Contains(err.Error(), "available: bugfix, nightly") {
+		t.Fatalf("expected unknown strategy error, got %v", err)
+	}
+	cfg.DefaultStrategy = ""
+	if name, steps, err := selectStrategy(cfg, ""); err != nil || name != "" || len(steps) != 1 {
+		t.Fatalf("expected the unnamed strategy, got %q %d %v", name, len(steps), err)
+	}
+}
+
+func TestPrintStrategies(t *testing.T) {
+	cfg := runtimeConfig{}
+	if err := parseConfigBytes([]byte(namedStrategiesConfig), &cfg); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	cfg.Strategies["broken"] = []strategyStep{{Mode: "build", Repeat: 0, Goto: "nowhere"}}
+	var buf bytes.Buffer
+	printStrategies(&buf, cfg)
+	want := `strategy:
+  1. plan, iterations: 1
+broken:
+  1. build, iterations: 1, goto: nowhere
+  invalid: strategy step 1: goto target "nowhere" not found
+bugfix:
+  1. build, iterations: 1, overrides: harness_args="" model=strong
+nightly (default):
+  1. build [build], iterations: 10, until: stalled or no_unchecked_tasks
+  2. plan, iterations: 1, if: stalled and has_unchecked_tasks, goto: build (repeat 3)
+`
+	if buf.String() != want {
+		t.Fatalf("unexpected listing:\n%s", buf.String())
+	}
+}
+
+func TestParseArgsRun(t *testing.T) {
+	cfg, err := parseArgs([]string{"run", "nightly"})
+	if err != nil || cfg.strategyName != "nightly" || !cfg.runCommand || cfg.explicitMode {
+		t.Fatalf("unexpected run config: %+v err=%v", cfg, err)
+	}
+	cfg, err = parseArgs([]string{"run", "--list"})
+	if err != nil || cfg.mode != "strategies" {
+		t.Fatalf("unexpected list config: %+v err=%v", cfg, err)
+	}
+	if _, err := parseArgs([]string{"run", "a", "b"}); err == nil {
+		t.Fatalf("expected an error for two strategy names")
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// strategyOverrides replace run-wide settings for one strategy step. Unset
+// fields keep the run-wide value.
+type strategyOverrides struct {
+	Harness         string
+	HarnessArgs     *string
+	Model           string
+	PromptFile      string
+	AttemptTimeout  *time.Duration
+	OnVerifyFail    string
+	MaxFilesChanged *int
+	MaxCommits      *int
+	MaxFileSize     *int64
+	ForbiddenPaths  []string
+	NoPush          *bool
+}
+
+// strategyStepConfig is the effective configuration of a strategy step, as
+// logged at iteration start and recorded in the run report.
+type strategyStepConfig struct {
+	Step            int      `json:"step"`
+	ID              string   `json:"id,omitempty"`
+	Harness         string   `json:"harness"`
+	HarnessArgs     string   `json:"harness_args,omitempty"`
+	Model           string   `json:"model,omitempty"`
+	PromptFile      string   `json:"prompt_file"`
+	AttemptTimeout  string   `json:"attempt_timeout,omitempty"`
+	OnVerifyFail    string   `json:"on_verify_fail,omitempty"`
+	MaxFilesChanged int      `json:"max_files_changed,omitempty"`
+	MaxCommits      int      `json:"max_commits,omitempty"`
+	MaxFileSize     int64    `json:"max_file_size,omitempty"`
+	ForbiddenPaths  []string `json:"forbidden_paths,omitempty"`
+	NoPush          bool     `json:"no_push,omitempty"`
+	Overridden      []string `json:"overridden,omitempty"`
+}
+
+// strategyStepRun holds the settings a strategy step runs with.
+type strategyStepRun struct {
+	cfg         modeConfig
+	fileCfg     runtimeConfig
+	harness     string
+	harnessArgs string
+	noPush      bool
+}
+
+// assignStrategyOverride parses a per-step override key. It reports false
+// for keys that are not overrides.
+func assignStrategyOverride(o *strategyOverrides, key, value string) bool {
+	switch key {
+	case "harness":
+		o.Harness = value
+	case "harness_args":
+		o.HarnessArgs = &value
+	case "model":
+		o.Model = value
+	case "prompt", "prompt_file":
+		o.PromptFile = value
+	case "attempt_timeout":
+		if v, err := time.ParseDuration(value); err == nil && v >= 0 {
+			o.AttemptTimeout = &v
+		}
+	case "on_verify_fail":
+		o.OnVerifyFail = value
+	case "max_files_changed":
+		if v, err := strconv.Atoi(value); err == nil && v >= 0 {
+			o.MaxFilesChanged = &v
+		}
+	case "max_commits_per_iteration":
+		if v, err := strconv.Atoi(value); err == nil && v >= 0 {
+			o.MaxCommits = &v
+		}
+	case "max_file_size":
+		if v, ok := parseByteSize(value); ok {
+			o.MaxFileSize = &v
+		}
+	case "forbidden_paths":
+		o.ForbiddenPaths = splitCommaList(value)
+	case "no_push":
+		if v, ok := parseBool(value); ok {
+			o.NoPush = &v
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// resolveStrategyStep applies a step's overrides to the run-wide settings.
+// It runs each time the step starts, so it sees the current config.
+func resolveStrategyStep(idx int, step strategyStep, cfg modeConfig, fileCfg runtimeConfig, harness, harnessArgs string, noPush bool) strategyStepRun {
+	o := step.Overrides
+	run := strategyStepRun{cfg: cfg, fileCfg: fileCfg, harness: harness, harnessArgs: harnessArgs, noPush: noPush}
+	run.cfg.mode = step.Mode
+	run.cfg.promptFile = promptForMode(step.Mode)
+	var overridden []string
+	if o.Harness != "" {
+		run.harness = o.Harness
+		overridden = append(overridden, "harness")
+	}
+	if o.HarnessArgs != nil {
+		run.harnessArgs = *o.HarnessArgs
+		overridden = append(overridden, "harness_args")
+	}
+	if o.Model != "" {
+		// runMode picks it as the base model unless the task names one.
+		run.cfg.stepModel = o.Model
+		overridden = append(overridden, "model")
+	}
+	if o.PromptFile != "" {
+		run.cfg.promptFile = o.PromptFile
+		overridden = append(overridden, "prompt_file")
+	}
+	if o.AttemptTimeout != nil {
+		run.cfg.AttemptTimeout = *o.AttemptTimeout
+		overridden = append(overridden, "attempt_timeout")
+	}
+	if o.OnVerifyFail != "" {
+		run.fileCfg.OnVerifyFail = o.OnVerifyFail
+		overridden = append(overridden, "on_verify_fail")
+	}
+	if o.MaxFilesChanged != nil {
+		run.fileCfg.MaxFilesChanged = *o.MaxFilesChanged
+		overridden = append(overridden, "max_files_changed")
+	}
+	if o.MaxCommits != nil {
+		run.fileCfg.MaxCommits = *o.MaxCommits
+		overridden = append(overridden, "max_commits_per_iteration")
+	}
+	if o.MaxFileSize != nil {
+		run.fileCfg.MaxFileSize = *o.MaxFileSize
+		overridden = append(overridden, "max_file_size")
+	}
+	if o.ForbiddenPaths != nil {
+		run.fileCfg.ForbiddenPaths = o.ForbiddenPaths
+		overridden = append(overridden, "forbidden_paths")
+	}
+	if o.NoPush != nil {
+		run.noPush = *o.NoPush
+		run.fileCfg.NoPush = *o.NoPush
+		overridden = append(overridden, "no_push")
+	}
+
+	// The task can still pick its own model; this is the step's default.
+	model, _ := selectBaseModel(run.fileCfg, run.cfg.stepModel, step.Mode, planTask{})
+	effective := &strategyStepConfig{
+		Step:            idx + 1,
+		ID:              step.ID,
+		Harness:         run.harness,
+		HarnessArgs:     run.harnessArgs,
+		Model:           model,
+		PromptFile:      run.cfg.promptFile,
+		OnVerifyFail:    run.fileCfg.OnVerifyFail,
+		MaxFilesChanged: run.fileCfg.MaxFilesChanged,
+		MaxCommits:      run.fileCfg.MaxCommits,
+		MaxFileSize:     run.fileCfg.MaxFileSize,
+		ForbiddenPaths:  run.fileCfg.ForbiddenPaths,
+		NoPush:          run.noPush,
+		Overridden:      overridden,
+	}
+	if run.cfg.AttemptTimeout > 0 {
+		effective.AttemptTimeout = run.cfg.AttemptTimeout.String()
+	}
+	run.cfg.step = effective
+	return run
+}
+
+// describe returns the header printed when a strategy step starts.
+func (c *strategyStepConfig) describe(mode string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Strategy step %d", c.Step)
+	if c.ID != "" {
+		fmt.Fprintf(&b, " (%s)", c.ID)
+	}
+	fmt.Fprintf(&b, ": %s with %s", mode, c.Harness)
+	if c.Model != "" {
+		fmt.Fprintf(&b, ", model %s", c.Model)
+	}
+	if len(c.Overridden) > 0 {
+		fmt.Fprintf(&b, " [overrides: %s]", strings.Join(c.Overridden, ", "))
+	}
+	return b.String()
+}
+
+// describe lists the set overrides as key=value pairs.
+func (o strategyOverrides) describe() string {
+	var parts []string
+	add := func(key, value string) {
+		parts = append(parts, key+"="+value)
+	}
+	if o.Harness != "" {
+		add("harness", o.Harness)
+	}
+	if o.HarnessArgs != nil {
+		add("harness_args", fmt.Sprintf("%q", *o.HarnessArgs))
+	}
+	if o.Model != "" {
+		add("model", o.Model)
+	}
+	if o.PromptFile != "" {
+		add("prompt", o.PromptFile)
+	}
+	if o.AttemptTimeout != nil {
+		add("attempt_timeout", o.AttemptTimeout.String())
+	}
+	if o.OnVerifyFail != "" {
+		add("on_verify_fail", o.OnVerifyFail)
+	}
+	if o.MaxFilesChanged != nil {
+		add("max_files_changed", strconv.Itoa(*o.MaxFilesChanged))
+	}
+	if o.MaxCommits != nil {
+		add("max_commits_per_iteration", strconv.Itoa(*o.MaxCommits))
+	}
+	if o.MaxFileSize != nil {
+		add("max_file_size", strconv.FormatInt(*o.MaxFileSize, 10))
+	}
+	if o.ForbiddenPaths != nil {
+		add("forbidden_paths", strings.Join(o.ForbiddenPaths, ","))
+	}
+	if o.NoPush != nil {
+		add("no_push", strconv.FormatBool(*o.NoPush))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveStrategyStep(t *testing.T) {
+	var step strategyStep
+	for _, kv := range [][2]string{
+		{"mode", "plan"},
+		{"id", "replan"},
+		{"harness", "codex"},
+		{"model", "big"},
+		{"prompt", "PROMPT_replan.md"},
+		{"attempt_timeout", "10m"},
+		{"on_verify_fail", "hard_reset"},
+		{"max_files_changed", "0"},
+		{"forbidden_paths", "src, vendor"},
+		{"no_push", "true"},
+	} {
+		assignStrategyField(&step, kv[0], kv[1])
+	}
+
+	fileCfg := runtimeConfig{ModelDefault: "small", ModelFlag: "--model", OnVerifyFail: "soft_reset", MaxFilesChanged: 20, MaxCommits: 2}
+	run := resolveStrategyStep(1, step, modeConfig{AttemptTimeout: time.Minute}, fileCfg, "claude", "-p --model small", false)
+
+	if run.harness != "codex" || run.harnessArgs != "-p --model small" || run.cfg.stepModel != "big" || !run.noPush {
+		t.Fatalf("unexpected harness settings: %+v", run)
+	}
+	if run.cfg.mode != "plan" || run.cfg.promptFile != "PROMPT_replan.md" || run.cfg.AttemptTimeout != 10*time.Minute {
+		t.Fatalf("unexpected mode config: %+v", run.cfg)
+	}
+	if run.fileCfg.OnVerifyFail != "hard_reset" || run.fileCfg.MaxFilesChanged != 0 || run.fileCfg.MaxCommits != 2 {
+		t.Fatalf("unexpected runtime config: %+v", run.fileCfg)
+	}
+	if fileCfg.OnVerifyFail != "soft_reset" {
+		t.Fatalf("overrides must not leak into the run-wide config")
+	}
+
+	eff := run.cfg.step
+	want := []string{"harness", "model", "prompt_file", "attempt_timeout", "on_verify_fail", "max_files_changed", "forbidden_paths", "no_push"}
+	if eff.Step != 2 || eff.ID != "replan" || eff.Model != "big" || eff.AttemptTimeout != "10m0s" || !reflect.DeepEqual(eff.Overridden, want) {
+		t.Fatalf("unexpected effective config: %+v", eff)
+	}
+	if got := eff.describe("plan"); got != "Strategy step 2 (replan): plan with codex, model big [overrides: "+strings.Join(want, ", ")+"]" {
+		t.Fatalf("unexpected header: %q", got)
+	}
+
+	plain := resolveStrategyStep(0, strategyStep{Mode: "build"}, modeConfig{}, fileCfg, "claude", "-p", false)
+	if plain.harnessArgs != "-p" || plain.cfg.promptFile != "PROMPT_build.md" || len(plain.cfg.step.Overridden) != 0 {
+		t.Fatalf("a step without overrides should keep the run-wide settings: %+v", plain)
+	}
+}
+
+func TestRunStrategyAppliesStepOverrides(t *testing.T) {
+	chdirTemp(t, t.TempDir())
+
+	origRunMode := runMode
+	defer func() { runMode = origRunMode }()
+	var harnesses []string
+	runMode = func(ctx context.Context, cfg modeConfig, fileCfg runtimeConfig, runner runtimeExec, state raufState, gitAvailable bool, branch, planPath, harness, harnessArgs string, noPush bool, logDir string, retryEnabled bool, retryMaxAttempts int, retryBackoffBase, retryBackoffMax time.Duration, retryJitter bool, retryMatch []string, startNoProgress int, stdin io.Reader, stdout io.Writer, report *RunReport) (iterationResult, error) {
+		harnesses = append(harnesses, harness+"/"+fileCfg.OnVerifyFail)
+		report.Iterations = append(report.Iterations, IterationStats{Mode: cfg.mode, Step: cfg.step})
+		return iterationResult{}, nil
+	}
+
+	fileCfg := runtimeConfig{OnVerifyFail: "soft_reset", Strategy: []strategyStep{
+		{Mode: "plan", Overrides: strategyOverrides{Harness: "codex"}},
+		{Mode: "build", Overrides: strategyOverrides{OnVerifyFail: "hard_reset"}},
+	}}
+	report := &RunReport{}
+	if err := runStrategy(context.Background(), modeConfig{}, fileCfg, runtimeExec{Quiet: true}, raufState{}, false, "", "", "claude", "", true, "logs", false, 0, 0, 0, false, nil, nil, nil, report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(harnesses, ","); got != "codex/soft_reset,claude/hard_reset" {
+		t.Fatalf("unexpected per-step settings: %s", got)
+	}
+	if len(report.Iterations) != 2 || report.Iterations[0].Step == nil || report.Iterations[1].Step.Step != 2 || report.Iterations[1].Step.OnVerifyFail != "hard_reset" {
+		t.Fatalf("expected effective step config in the report: %+v", report.Iterations)
+	}
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
+	"io"
 	"testing"
+	"time"
 )
 
 func TestShouldRunStep_Comprehensive(t *testing.T) {
@@ -71,10 +74,77 @@ func TestShouldRunStep_Comprehensive(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := shouldRunStep(tc.step, tc.result)
+			got := shouldRunStep(tc.step, strategyContext{Last: tc.result})
 			if got != tc.expected {
 				t.Errorf("shouldRunStep() = %v, want %v", got, tc.expected)
 			}
 		})
 	}
 }
+
+func TestStrategyCounterConditions(t *testing.T) {
+	sc := strategyContext{State: raufState{ConsecutiveVerifyFails: 3}}
+	sc.Escalations = 1
+	sc.Iterations = 7
+
+	tests := []struct {
+		cond string
+		want bool
+	}{
+		{"consecutive_verify_fails >= 3", true},
+		{"consecutive_verify_fails>3", false},
+		{"iterations < 10", true},
+		{"escalations == 1", true},
+		{"escalated", true},
+	}
+	for _, tc := range tests {
+		if got := shouldRunStep(strategyStep{If: tc.cond}, sc); got != tc.want {
+			t.Errorf("if %q = %v, want %v", tc.cond, got, tc.want)
+		}
+	}
+	if shouldContinueUntil(strategyStep{Until: "consecutive_verify_fails >= 3"}, sc) {
+		t.Errorf("until should stop once the counter condition holds")
+	}
+	if !shouldContinueUntil(strategyStep{Until: "iterations >= 8"}, sc) {
+		t.Errorf("until should continue while the counter condition is false")
+	}
+}
+
+func TestRunStrategyPropagatesState(t *testing.T) {
+	chdirTemp(t, t.TempDir())
+
+	origRunMode := runMode
+	defer func() { runMode = origRunMode }()
+	var seen []raufState
+	runMode = func(ctx context.Context, cfg modeConfig, fileCfg runtimeConfig, runner runtimeExec, state raufState, gitAvailable bool, branch, planPath, harness, harnessArgs string, noPush bool, logDir string, retryEnabled bool, retryMaxAttempts int, retryBackoffBase, retryBackoffMax time.Duration, retryJitter bool, retryMatch []string, startNoProgress int, stdin io.Reader, stdout io.Writer, report *RunReport) (iterationResult, error) {
+		seen = append(seen, state)
+		if cfg.mode == "build" {
+			state.EscalationCount++
+			state.ConsecutiveVerifyFails++
+			state.RecoveryMode = "verify"
+			if err := saveState(state); err != nil {
+				t.Fatalf("save failed: %v", err)
+			}
+			return iterationResult{VerifyStatus: "fail"}, nil
+		}
+		return iterationResult{}, nil
+	}
+
+	fileCfg := runtimeConfig{Strategy: []strategyStep{
+		{Mode: "build", Iterations: 3, Until: "consecutive_verify_fails >= 2"},
+		{Mode: "plan", Iterations: 1, If: "escalated"},
+	}}
+	if err := runStrategy(context.Background(), modeConfig{}, fileCfg, runtimeExec{}, raufState{}, false, "", "", "", "", true, "logs", false, 0, 0, 0, false, nil, nil, nil, &RunReport{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(seen) != 3 {
+		t.Fatalf("expected 2 build iterations and 1 plan iteration, got %d calls", len(seen))
+	}
+	if seen[1].ConsecutiveVerifyFails != 1 {
+		t.Fatalf("second build iteration should see the first one's state: %+v", seen[1])
+	}
+	plan := seen[2]
+	if plan.EscalationCount != 2 || plan.RecoveryMode != "verify" {
+		t.Fatalf("plan step should see state from the build step: %+v", plan)
+	}
+}